@@ -1,4 +1,3 @@
 #!/bin/bash
 
-[ -f spotify/api.go.bak ] && mv -f spotify/api.go{.bak,}
-[ -f lyrics/genius.go.bak ] && mv -f lyrics/genius.go{.bak,}
\ No newline at end of file
+[ -f spotify/api.go.bak ] && mv -f spotify/api.go{.bak,}
\ No newline at end of file
@@ -3,8 +3,48 @@ package config
 // Config represents the abstraction of the parsed
 // configuration file
 type Config struct {
-	Folder  string              `yaml:"folder"`
-	Aliases []map[string]string `yaml:"aliases"`
+	Folder     string              `yaml:"folder"`
+	Aliases    []map[string]string `yaml:"aliases"`
+	Spotify    Spotify             `yaml:"spotify"`
+	Lyrics     Lyrics              `yaml:"lyrics"`
+	Downloader Downloader          `yaml:"downloader"`
+}
+
+// Spotify wraps the settings used while
+// interacting with Spotify APIs
+type Spotify struct {
+	Retries int `yaml:"retries"`
+}
+
+// Downloader wraps the settings of the command
+// used to download media assets: if no backend
+// is given, it gets told by the path binary name
+// or detected among the installed ones
+type Downloader struct {
+	Backend string   `yaml:"backend"`
+	Path    string   `yaml:"path"`
+	Args    []string `yaml:"args"`
+}
+
+// Lyrics wraps the settings used while
+// looking for songs lyrics
+type Lyrics struct {
+	Providers []LyricsProvider `yaml:"providers"`
+}
+
+// LyricsProvider wraps the settings of a lyrics
+// provider, queried in the order they are listed
+type LyricsProvider struct {
+	Name    string `yaml:"name"`
+	Enabled *bool  `yaml:"enabled"`
+	Token   string `yaml:"token"`
+	URL     string `yaml:"url"`
+}
+
+// IsEnabled returns whether the provider is enabled,
+// which is the default if not told otherwise
+func (provider LyricsProvider) IsEnabled() bool {
+	return provider.Enabled == nil || *provider.Enabled
 }
 
 // URI returns the URI corresponding
@@ -33,5 +33,9 @@ func process(cfg *Config) (*Config, error) {
 		cfg.Folder = RelativeTo(strings.ReplaceAll(cfg.Folder, "~/", ""), HomePath)
 	}
 
+	if strings.HasPrefix(cfg.Downloader.Path, "~/") {
+		cfg.Downloader.Path = RelativeTo(strings.TrimPrefix(cfg.Downloader.Path, "~/"), HomePath)
+	}
+
 	return cfg, nil
 }
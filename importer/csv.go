@@ -0,0 +1,96 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	csvColumnsURI    = []string{"track uri", "spotify uri", "uri"}
+	csvColumnsTitle  = []string{"track name", "title", "name", "track"}
+	csvColumnsArtist = []string{"artist name(s)", "artist name", "artist", "artists"}
+	csvColumnsAlbum  = []string{"album name", "album"}
+)
+
+// CSVParser is the parser implementation for CSV files,
+// as the ones exported by Exportify
+type CSVParser struct {
+	Parser
+}
+
+// Name returns a human readable name for the parser
+func (p CSVParser) Name() string {
+	return "CSV"
+}
+
+// Parse reads the track entries from given file path
+func (p CSVParser) Parse(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%s is empty", path)
+	}
+
+	var (
+		entries   []Entry
+		colURI    = csvColumn(rows[0], csvColumnsURI)
+		colTitle  = csvColumn(rows[0], csvColumnsTitle)
+		colArtist = csvColumn(rows[0], csvColumnsArtist)
+		colAlbum  = csvColumn(rows[0], csvColumnsAlbum)
+	)
+	if colURI < 0 && colTitle < 0 {
+		return nil, fmt.Errorf("%s has neither a track URI nor a track name column", path)
+	}
+
+	for _, row := range rows[1:] {
+		entry := Entry{
+			URI:    csvField(row, colURI),
+			Title:  csvField(row, colTitle),
+			Artist: csvField(row, colArtist),
+			Album:  csvField(row, colAlbum),
+		}
+
+		// multiple artists are comma separated, the first one is enough
+		entry.Artist = strings.TrimSpace(strings.Split(entry.Artist, ",")[0])
+		if !strings.HasPrefix(entry.URI, "spotify:track:") {
+			entry.URI = ""
+		}
+
+		if len(entry.URI) > 0 || len(entry.Title) > 0 {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+func csvColumn(header []string, names []string) int {
+	for _, name := range names {
+		for i, column := range header {
+			if strings.ToLower(strings.TrimSpace(column)) == name {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func csvField(row []string, column int) string {
+	if column < 0 || column >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[column])
+}
@@ -0,0 +1,49 @@
+package importer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Entry represents a single track row read from an imported file
+type Entry struct {
+	Artist string
+	Title  string
+	Album  string
+	URI    string
+}
+
+// Query returns the free text representation of the entry,
+// usable to search for it
+func (e Entry) Query() string {
+	if len(e.Artist) == 0 {
+		return e.Title
+	}
+
+	return fmt.Sprintf("%s - %s", e.Artist, e.Title)
+}
+
+// Parser defines the generic interface on which every
+// track list format parser should be basing its logic
+type Parser interface {
+	Name() string
+	Parse(path string) ([]Entry, error)
+}
+
+// For returns the parser suitable for given file path
+func For(path string) Parser {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return new(CSVParser)
+	case ".xml":
+		return new(ITunesParser)
+	}
+
+	return new(TextParser)
+}
+
+// Parse reads the track entries from given file path
+func Parse(path string) ([]Entry, error) {
+	return For(path).Parse(path)
+}
@@ -0,0 +1,149 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ITunesParser is the parser implementation for iTunes
+// and Apple Music library XML files
+type ITunesParser struct {
+	Parser
+}
+
+// plistDict is a plist dictionary keeping its keys order
+type plistDict struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// Name returns a human readable name for the parser
+func (p ITunesParser) Name() string {
+	return "iTunes XML"
+}
+
+// Parse reads the track entries from given file path
+func (p ITunesParser) Parse(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	root, err := plistDecode(xml.NewDecoder(file))
+	if err != nil {
+		return nil, err
+	}
+
+	library, ok := root.(*plistDict)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an iTunes library", path)
+	}
+
+	tracks, ok := library.values["Tracks"].(*plistDict)
+	if !ok {
+		return nil, fmt.Errorf("%s does not contain any track", path)
+	}
+
+	var entries []Entry
+	for _, key := range tracks.keys {
+		t, ok := tracks.values[key].(*plistDict)
+		if !ok {
+			continue
+		}
+
+		entry := Entry{
+			Title:  plistString(t, "Name"),
+			Artist: plistString(t, "Artist"),
+			Album:  plistString(t, "Album"),
+		}
+		if len(entry.Title) > 0 {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// plistDecode returns the first plist value found in given decoder
+func plistDecode(decoder *xml.Decoder) (interface{}, error) {
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("No plist value found")
+		} else if err != nil {
+			return nil, err
+		}
+
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local != "plist" {
+			return plistDecodeElement(decoder, start)
+		}
+	}
+}
+
+func plistDecodeElement(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	switch start.Name.Local {
+	case "dict":
+		dict := &plistDict{values: make(map[string]interface{})}
+		var key string
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			switch element := token.(type) {
+			case xml.StartElement:
+				if element.Name.Local == "key" {
+					if err := decoder.DecodeElement(&key, &element); err != nil {
+						return nil, err
+					}
+					continue
+				}
+
+				value, err := plistDecodeElement(decoder, element)
+				if err != nil {
+					return nil, err
+				}
+				dict.keys = append(dict.keys, key)
+				dict.values[key] = value
+			case xml.EndElement:
+				return dict, nil
+			}
+		}
+	case "array":
+		var array []interface{}
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			switch element := token.(type) {
+			case xml.StartElement:
+				value, err := plistDecodeElement(decoder, element)
+				if err != nil {
+					return nil, err
+				}
+				array = append(array, value)
+			case xml.EndElement:
+				return array, nil
+			}
+		}
+	default:
+		var text string
+		if err := decoder.DecodeElement(&text, &start); err != nil {
+			return nil, err
+		}
+		return text, nil
+	}
+}
+
+func plistString(dict *plistDict, key string) string {
+	if value, ok := dict.values[key].(string); ok {
+		return value
+	}
+	return ""
+}
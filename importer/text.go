@@ -0,0 +1,42 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/streambinder/spotitube/system"
+)
+
+// TextParser is the parser implementation for plain text
+// files listing a "Artist - Title" entry per line
+type TextParser struct {
+	Parser
+}
+
+// Name returns a human readable name for the parser
+func (p TextParser) Name() string {
+	return "text"
+}
+
+// Parse reads the track entries from given file path
+func (p TextParser) Parse(path string) ([]Entry, error) {
+	if !system.FileExists(path) {
+		return nil, fmt.Errorf(fmt.Sprintf("%s does not exist", path))
+	}
+
+	var entries []Entry
+	for _, line := range system.FileReadLines(path) {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if parts := strings.SplitN(line, " - ", 2); len(parts) == 2 {
+			entries = append(entries, Entry{Artist: strings.TrimSpace(parts[0]), Title: strings.TrimSpace(parts[1])})
+		} else {
+			entries = append(entries, Entry{Title: line})
+		}
+	}
+
+	return entries, nil
+}
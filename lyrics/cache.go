@@ -0,0 +1,80 @@
+package lyrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/streambinder/spotitube/system"
+)
+
+const (
+	// CacheMissDuration is the time after which a lookup which found
+	// no lyrics is forgotten, so that providers get queried again
+	CacheMissDuration = 7 * 24 * time.Hour
+)
+
+// CacheEntry represents the outcome of a lyrics lookup:
+// a nil Result means that no lyrics have been found
+type CacheEntry struct {
+	Result *Result
+	Time   time.Time
+}
+
+// Cache represents a mapping between Spotify ID and lyrics lookup outcome
+// It's used to avoid querying providers again for already looked up songs.
+type Cache struct {
+	Entries map[string]CacheEntry
+	mutex   sync.Mutex
+}
+
+// NewCache returns an empty Cache object
+func NewCache() *Cache {
+	return &Cache{Entries: make(map[string]CacheEntry)}
+}
+
+// OpenCache loads the Cache object dumped at input passed path,
+// returning an empty one if it cannot be read
+func OpenCache(path string) *Cache {
+	cache := NewCache()
+	if system.FileExists(path) {
+		if err := system.FetchGob(path, cache); err != nil || cache.Entries == nil {
+			return NewCache()
+		}
+	}
+	return cache
+}
+
+// Get returns the cached lookup result for input id, along with a boolean
+// telling whether the lookup is known at all: stale misses are not
+func (cache *Cache) Get(id string) (*Result, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	entry, ok := cache.Entries[id]
+	if !ok {
+		return nil, false
+	}
+
+	if entry.Result == nil && time.Since(entry.Time) > CacheMissDuration {
+		delete(cache.Entries, id)
+		return nil, false
+	}
+
+	return entry.Result, true
+}
+
+// Put stores input result, which can be nil if no lyrics have been found, for input id
+func (cache *Cache) Put(id string, result *Result) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.Entries[id] = CacheEntry{Result: result, Time: time.Now()}
+}
+
+// Sync flushes cache object on disk at input passed path
+func (cache *Cache) Sync(path string) error {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return system.DumpGob(path, cache)
+}
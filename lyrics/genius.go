@@ -6,7 +6,6 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -15,14 +14,14 @@ import (
 )
 
 const (
-	geniusToken = ""
-	geniusAPI   = "https://api.genius.com/search?q=%s+%s"
+	geniusAPI = "https://api.genius.com/search?q=%s+%s"
 )
 
 // GeniusProvider is the provider implementation which uses as source
 // Genius lyrics platform
 type GeniusProvider struct {
 	Provider
+	Token string
 }
 
 // Name returns a human readable name for the provider
@@ -31,60 +30,73 @@ func (p GeniusProvider) Name() string {
 }
 
 // Query returns a lyrics text for give title and artist
-func (p GeniusProvider) Query(title, artist string) (string, error) {
-	var token = geniusToken
-	if len(token) != 64 {
-		token = os.Getenv("GENIUS_TOKEN")
-	}
-	if len(token) != 64 {
-		return "", fmt.Errorf("Cannot fetch lyrics from Genius without a valid token")
-	}
-
+func (p GeniusProvider) Query(title, artist string) (*Result, error) {
 	encURL, err := url.Parse(fmt.Sprintf(geniusAPI, url.QueryEscape(title), url.QueryEscape(artist)))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	req, err := http.NewRequest(http.MethodGet, encURL.String(), nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", p.Token))
 
 	res, err := system.Client.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
+	defer res.Body.Close()
+
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	var result map[string]interface{}
 	if err := json.Unmarshal([]byte(body), &result); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	var (
-		url  string
-		hits = result["response"].(map[string]interface{})["hits"].([]interface{})
+		url   string
+		score = -1
+		match = new(Result)
+		hits  = result["response"].(map[string]interface{})["hits"].([]interface{})
 	)
 	for _, hit := range hits {
 		hitRes := hit.(map[string]interface{})["result"].(map[string]interface{})
 		hitTitle := strings.TrimSpace(hitRes["title"].(string))
 		hitArtist := strings.TrimSpace(hitRes["primary_artist"].(map[string]interface{})["name"].(string))
 
-		if strings.Contains(hitTitle, title) && strings.Contains(hitArtist, artist) {
+		if hitScore := system.Similarity(hitTitle, title) + system.Similarity(hitArtist, artist); hitScore > score {
 			url = strings.TrimSpace(hitRes["url"].(string))
-			break
+			score = hitScore
+			match.Title, match.Artist = hitTitle, hitArtist
 		}
 	}
 
 	if len(url) == 0 {
-		return "", fmt.Errorf("Genius lyrics not found")
+		return nil, &MissError{Reason: "Genius lyrics not found"}
+	}
+
+	doc, err := goquery.NewDocument(url)
+	if err != nil {
+		return nil, err
+	}
+
+	// lyrics are split among several containers, using line breaks,
+	// on the current layout, and in a single one on the legacy layout
+	var paragraphs []string
+	doc.Find("[data-lyrics-container]").Each(func(_ int, container *goquery.Selection) {
+		container.Find("br").ReplaceWithHtml("\n")
+		paragraphs = append(paragraphs, container.Text())
+	})
+	if len(paragraphs) == 0 {
+		paragraphs = append(paragraphs, doc.Find(".lyrics").Eq(0).Text())
 	}
 
-	doc, _ := goquery.NewDocument(url)
-	return strings.TrimSpace(unidecode.Unidecode(doc.Find(".lyrics").Eq(0).Text())), nil
+	match.Text = strings.TrimSpace(unidecode.Unidecode(strings.Join(paragraphs, "\n")))
+	return match, nil
 }
@@ -0,0 +1,99 @@
+package lyrics
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	regLRCTimestamp = regexp.MustCompile(`^\[(\d+):(\d{1,2})(?:[.:](\d{1,3}))?\]`)
+	regLRCTag       = regexp.MustCompile(`^\[([a-zA-Z#]+):(.*)\]$`)
+)
+
+// Line represents a single time-stamped lyrics line
+type Line struct {
+	Time time.Duration
+	Text string
+}
+
+// Synced represents time-stamped lyrics, sorted by time
+type Synced []Line
+
+// ParseLRC parses given LRC-formatted text, honoring its offset tag
+// and lines carrying more than a timestamp
+func ParseLRC(text string) (Synced, error) {
+	var (
+		synced Synced
+		offset time.Duration
+	)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+
+		var times []time.Duration
+		for match := regLRCTimestamp.FindStringSubmatch(line); match != nil; match = regLRCTimestamp.FindStringSubmatch(line) {
+			minutes, _ := strconv.Atoi(match[1])
+			seconds, _ := strconv.Atoi(match[2])
+			// fractions can be tenths, hundredths or thousandths of second
+			millis, _ := strconv.Atoi((match[3] + "000")[:3])
+			times = append(times, time.Duration(minutes)*time.Minute+
+				time.Duration(seconds)*time.Second+
+				time.Duration(millis)*time.Millisecond)
+			line = strings.TrimSpace(line[len(match[0]):])
+		}
+
+		if len(times) == 0 {
+			if match := regLRCTag.FindStringSubmatch(line); match != nil && strings.EqualFold(match[1], "offset") {
+				if millis, err := strconv.Atoi(strings.TrimSpace(match[2])); err == nil {
+					offset = time.Duration(millis) * time.Millisecond
+				}
+			}
+			continue
+		}
+
+		for _, t := range times {
+			synced = append(synced, Line{Time: t, Text: line})
+		}
+	}
+
+	if len(synced) == 0 {
+		return nil, fmt.Errorf("No time-stamped line found")
+	}
+
+	// positive offsets make lines show up sooner
+	for i := range synced {
+		if synced[i].Time -= offset; synced[i].Time < 0 {
+			synced[i].Time = 0
+		}
+	}
+
+	sort.SliceStable(synced, func(i, j int) bool {
+		return synced[i].Time < synced[j].Time
+	})
+	return synced, nil
+}
+
+// Text returns the plain text representation of the lyrics
+func (s Synced) Text() string {
+	var lines []string
+	for _, line := range s {
+		lines = append(lines, line.Text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// LRC returns the LRC-formatted representation of the lyrics
+func (s Synced) LRC() string {
+	var content string
+	for _, line := range s {
+		content += fmt.Sprintf("[%02d:%02d.%02d]%s\n",
+			int(line.Time/time.Minute),
+			int(line.Time%time.Minute/time.Second),
+			int(line.Time%time.Second/(10*time.Millisecond)),
+			line.Text)
+	}
+	return content
+}
@@ -0,0 +1,120 @@
+package lyrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/streambinder/spotitube/system"
+)
+
+const (
+	// LRCLibURL is the base URL of the public LRCLIB instance
+	LRCLibURL       = "https://lrclib.net"
+	lrclibSearch    = "%s/api/search?track_name=%s&artist_name=%s"
+	lrclibUserAgent = "spotitube (https://github.com/streambinder/spotitube)"
+)
+
+type lrclibEntry struct {
+	TrackName    string `json:"trackName"`
+	ArtistName   string `json:"artistName"`
+	Instrumental bool   `json:"instrumental"`
+	PlainLyrics  string `json:"plainLyrics"`
+	SyncedLyrics string `json:"syncedLyrics"`
+}
+
+// LRCLibProvider is the provider implementation which uses as source
+// LRCLIB lyrics platform, or any other instance exposing its API at URL
+type LRCLibProvider struct {
+	Provider
+	URL string
+}
+
+// Name returns a human readable name for the provider
+func (p LRCLibProvider) Name() string {
+	return "LRCLIB"
+}
+
+// Query returns a lyrics text for give title and artist,
+// along with time-stamped lyrics, if any
+func (p LRCLibProvider) Query(title, artist string) (*Result, error) {
+	entry, err := p.search(title, artist)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Title:  entry.TrackName,
+		Artist: entry.ArtistName,
+		Text:   strings.TrimSpace(entry.PlainLyrics),
+	}
+	if synced, err := ParseLRC(entry.SyncedLyrics); err == nil {
+		result.Synced = synced
+		if len(result.Text) == 0 {
+			result.Text = synced.Text()
+		}
+	}
+
+	return result, nil
+}
+
+func (p LRCLibProvider) search(title, artist string) (*lrclibEntry, error) {
+	encURL, err := url.Parse(fmt.Sprintf(lrclibSearch, p.URL, url.QueryEscape(title), url.QueryEscape(artist)))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, encURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("User-Agent", lrclibUserAgent)
+
+	res, err := system.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("LRCLIB responded with status %d", res.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []lrclibEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, err
+	}
+
+	var (
+		match *lrclibEntry
+		score = -1
+	)
+	for i, entry := range entries {
+		if entry.Instrumental {
+			continue
+		}
+
+		entryScore := system.Similarity(entry.TrackName, title) + system.Similarity(entry.ArtistName, artist)
+		// time-stamped lyrics win over equally matching plain ones
+		if len(entry.SyncedLyrics) > 0 {
+			entryScore++
+		}
+
+		if entryScore > score {
+			match, score = &entries[i], entryScore
+		}
+	}
+
+	if match == nil {
+		return nil, &MissError{Reason: "LRCLIB lyrics not found"}
+	}
+	return match, nil
+}
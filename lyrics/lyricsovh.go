@@ -32,31 +32,44 @@ func (p OVHProvider) Name() string {
 }
 
 // Query returns a lyrics text for give title and artist
-func (p OVHProvider) Query(title, artist string) (string, error) {
+func (p OVHProvider) Query(title, artist string) (*Result, error) {
 	encURL, err := url.Parse(fmt.Sprintf(ovhAPI, url.QueryEscape(artist), url.QueryEscape(title)))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	req, err := http.NewRequest(http.MethodGet, encURL.String(), nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	res, err := system.Client.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, &MissError{Reason: "lyrics.ovh lyrics not found"}
+	} else if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("lyrics.ovh responded with status %d", res.StatusCode)
 	}
 
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	entry := new(ovhAPIEntry)
 	if err := json.Unmarshal(body, entry); err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return strings.TrimSpace(unidecode.Unidecode(entry.Lyrics)), nil
+	// lookups are exact, hence the song is the queried one
+	return &Result{
+		Title:  title,
+		Artist: artist,
+		Text:   strings.TrimSpace(unidecode.Unidecode(entry.Lyrics)),
+	}, nil
 }
@@ -1,16 +1,118 @@
 package lyrics
 
-// All return the array of usable providers
-func All() []Provider {
-	return []Provider{
-		new(GeniusProvider),
-		new(OVHProvider),
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gosimple/slug"
+	"github.com/streambinder/spotitube/system"
+)
+
+const (
+	// ProviderLRCLib is the identifier for LRCLIB lyrics provider
+	ProviderLRCLib = "lrclib"
+	// ProviderGenius is the identifier for Genius lyrics provider
+	ProviderGenius = "genius"
+	// ProviderOVH is the identifier for lyrics.ovh lyrics provider
+	ProviderOVH         = "ovh"
+	similarityThreshold = 60
+)
+
+var (
+	// DefaultProviders is the providers chain used if none is configured
+	DefaultProviders = []string{ProviderLRCLib, ProviderGenius, ProviderOVH}
+)
+
+// Options wraps the settings a provider is set up with
+type Options struct {
+	Token string
+	URL   string
+}
+
+// New returns the provider identified by given name, set up with given options
+func New(name string, opts Options) (Provider, error) {
+	switch strings.ToLower(name) {
+	case ProviderLRCLib:
+		url := opts.URL
+		if len(url) == 0 {
+			url = LRCLibURL
+		}
+		return &LRCLibProvider{URL: strings.TrimSuffix(url, "/")}, nil
+	case ProviderGenius:
+		if len(opts.Token) != 64 {
+			return nil, fmt.Errorf("Cannot fetch lyrics from Genius without a valid token")
+		}
+		return &GeniusProvider{Token: opts.Token}, nil
+	case ProviderOVH:
+		return new(OVHProvider), nil
 	}
+
+	return nil, fmt.Errorf("Unknown lyrics provider %s", name)
 }
 
 // Provider defines the generic interface on which every lyrics provider
 // should be basing its logic
 type Provider interface {
 	Name() string
-	Query(title, artist string) (string, error)
+	Query(title, artist string) (*Result, error)
+}
+
+// Result represents lyrics returned by a provider, along with
+// the title and artist of the song the provider found them for
+type Result struct {
+	Title  string
+	Artist string
+	Text   string
+	Synced Synced
+}
+
+// MissError is returned when a provider has successfully answered,
+// but without any lyrics valid for the queried song
+type MissError struct {
+	Reason string
+}
+
+// Error returns the string representation of the error
+func (e *MissError) Error() string {
+	return e.Reason
+}
+
+// Fetch queries given provider for lyrics of the song with given title and artist,
+// time-stamped ones included, if the provider gives any, and returns them once validated
+func Fetch(p Provider, title, artist string) (*Result, error) {
+	result, err := p.Query(title, artist)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := Validate(result, title, artist); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Validate returns an error if given result has no text or if it
+// is not resembling the song with given title and artist
+func Validate(result *Result, title, artist string) error {
+	if result == nil || len(strings.TrimSpace(result.Text)) == 0 {
+		return &MissError{Reason: "Lyrics are empty"}
+	}
+
+	if !resembling(result.Title, title) || !resembling(result.Artist, artist) {
+		return &MissError{Reason: fmt.Sprintf("Lyrics belong to \"%s - %s\", not to \"%s - %s\"",
+			result.Artist, result.Title, artist, title)}
+	}
+
+	return nil
+}
+
+func resembling(a, b string) bool {
+	// providers not giving any metadata back cannot be told wrong
+	if len(a) == 0 {
+		return true
+	}
+
+	slugA, slugB := slug.Make(a), slug.Make(b)
+	return strings.Contains(slugA, slugB) || strings.Contains(slugB, slugA) ||
+		system.Similarity(a, b) >= similarityThreshold
 }
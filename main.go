@@ -1,15 +1,19 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"hash/fnv"
 	"io/ioutil"
 	"math"
 	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -18,6 +22,7 @@ import (
 	"github.com/hako/durafmt"
 	"github.com/streambinder/spotitube/config"
 	"github.com/streambinder/spotitube/cui"
+	"github.com/streambinder/spotitube/importer"
 	"github.com/streambinder/spotitube/lyrics"
 	"github.com/streambinder/spotitube/provider"
 	"github.com/streambinder/spotitube/shell"
@@ -29,19 +34,35 @@ import (
 
 const (
 	version          = 31
-	cacheDuration    = 30 * time.Minute
 	concurrencyLimit = 100
+	consumeLibrary   = "library"
 )
 
 var (
 	// flags for separate flows
 	argCleanJunks bool
+	argMigrate    bool
 	argVersion    bool
+	argLogout     bool
+	argPush       system.StringsFlag
 	// flags for media sources
-	argLibrary   bool
-	argAlbums    system.StringsFlag
-	argPlaylists system.StringsFlag
-	argTracksFix system.StringsFlag
+	argLibrary      bool
+	argSavedAlbums  bool
+	argFollowed     bool
+	argAlbums       system.StringsFlag
+	argArtists      system.StringsFlag
+	argArtistGroups system.StringsFlag
+	argPlaylists    system.StringsFlag
+	argTracks       system.StringsFlag
+	argSearches     system.StringsFlag
+	argFromFiles    system.StringsFlag
+	argShows        system.StringsFlag
+	argShowEpisodes int
+	argShowSince    string
+	argTracksFix    system.StringsFlag
+	argInclude      system.StringsFlag
+	argExclude      system.StringsFlag
+	argConsume      system.StringsFlag
 	// flags for options
 	argFolder                string
 	argFlushCache            bool
@@ -51,12 +72,22 @@ var (
 	argDisablePlaylistFile   bool
 	argPlsFile               bool
 	argDisableLyrics         bool
+	argFlushLyrics           bool
+	argLyricsSidecar         bool
+	argFeaturingsTitle       bool
+	argAudioFeatures         bool
+	argCamelot               bool
 	argDisableUpdateCheck    bool
 	argDisableBrowserOpening bool
 	argDisableIndexing       bool
 	argAuthenticateOutside   bool
+	argAuthenticateHeadless  bool
+	argUser                  string
 	argInteractive           bool
 	argInput                 bool
+	argPrune                 bool
+	argPruneDryRun           bool
+	argPruneQuarantine       string
 	// flags for troubleshooting
 	argLog        bool
 	argDebug      bool
@@ -72,7 +103,14 @@ var (
 	artworks     = make(map[string]*[]byte)
 	playlists    []*track.Playlist
 	tracksFailed []*track.Track
+	tracksMutex  sync.Mutex
+	consumables  = make(map[string][]*track.Track)
+	sourcesIDs   = make(map[string]bool)
+	sourcesPaths = make(map[string]bool)
+	sourcesFail  bool
 	index        *track.TracksIndex
+	lyricsCache  *lyrics.Cache
+	lyricsChain  []lyrics.Provider
 
 	// routines
 	waitGroup     sync.WaitGroup
@@ -87,6 +125,8 @@ var (
 	// user paths
 	usrGob       = config.RelativeTo("%s_%s.gob", config.CachePath)
 	usrIndex     = config.RelativeTo("index.gob", config.CachePath)
+	usrLyrics    = config.RelativeTo("lyrics.gob", config.CachePath)
+	usrSession   = config.RelativeTo("session.gob", config.CachePath)
 	regUsrBinary = regexp.MustCompile(`spotitube\.[0-9]+`)
 )
 
@@ -98,7 +138,9 @@ func main() {
 	mainInit()
 	mainUI()
 	mainAuthenticate()
+	mainPush()
 	mainFetch()
+	mainPrune()
 	mainSearch()
 	mainExit()
 }
@@ -133,11 +175,6 @@ func mainEnvCheck() {
 		os.Exit(1)
 	}
 
-	if !shell.YoutubeDL().Exists() {
-		fmt.Println(fmt.Sprintf("%s command is not installed.", shell.YoutubeDL().Name()))
-		os.Exit(1)
-	}
-
 	if !shell.FFmpeg().Exists() {
 		fmt.Println(fmt.Sprintf("%s command is not installed.", shell.FFmpeg().Name()))
 		os.Exit(1)
@@ -162,13 +199,29 @@ func mainEnvCheck() {
 func mainFlagsParse() {
 	// separate flows
 	flag.BoolVar(&argCleanJunks, "clean-junks", false, "Scan for and clean junk files")
+	flag.BoolVar(&argMigrate, "migrate-tags", false, "Scan for and rewrite songs custom metadata from legacy comment frames into user defined text frames")
 	flag.BoolVar(&argVersion, "version", false, "Print version")
+	flag.BoolVar(&argLogout, "logout", false, "Drop stored Spotify session token")
+	flag.Var(&argPush, "push-playlist", "Local M3U or PLS playlist file to create or update on Spotify")
 
 	// media sources
 	flag.BoolVar(&argLibrary, "library", false, "Synchronize user library")
-	flag.Var(&argAlbums, "album", "Album URI to synchronize")
-	flag.Var(&argPlaylists, "playlist", "Playlist URI to synchronize")
+	flag.BoolVar(&argSavedAlbums, "saved-albums", false, "Synchronize albums saved into user library")
+	flag.BoolVar(&argFollowed, "followed-playlists", false, "Synchronize playlists owned or followed by user")
+	flag.Var(&argAlbums, "album", "Album URI or link to synchronize")
+	flag.Var(&argArtists, "artist", "Artist URI or link to synchronize")
+	flag.Var(&argArtistGroups, "artist-group", "Artist album groups to synchronize: album, single, compilation, appears_on (default album;single)")
+	flag.Var(&argPlaylists, "playlist", "Playlist URI or link to synchronize")
+	flag.Var(&argTracks, "track", "Track URI or link to synchronize")
+	flag.Var(&argSearches, "search", "Free text (as \"Artist - Title\") to search on Spotify and synchronize")
+	flag.Var(&argFromFiles, "from-file", "Track list file (\"Artist - Title\" lines, Exportify CSV or iTunes XML) to synchronize as playlist")
+	flag.Var(&argShows, "show", "Podcast show URI or link whose episodes to synchronize")
+	flag.IntVar(&argShowEpisodes, "show-episodes", 10, "Number of newest show episodes to synchronize (0 for all of them)")
+	flag.StringVar(&argShowSince, "show-since", "", "Synchronize show episodes published since given date (YYYY-MM-DD)")
 	flag.Var(&argTracksFix, "fix", "Offline song filename(s) which straighten the shot to")
+	flag.Var(&argInclude, "include", "Regex saved albums and followed playlists names or owners must match to be synchronized")
+	flag.Var(&argExclude, "exclude", "Regex saved albums and followed playlists names or owners must not match to be synchronized")
+	flag.Var(&argConsume, "consume", "Playlist URI or link (or \"library\" for liked songs) to synchronize and whose songs get removed from once synchronized")
 
 	// options
 	flag.StringVar(&argFolder, "folder", ".", "Folder to sync your tracks collection into")
@@ -179,12 +232,22 @@ func mainFlagsParse() {
 	flag.BoolVar(&argDisablePlaylistFile, "disable-playlist-file", false, "Disable automatic creation of playlists file")
 	flag.BoolVar(&argPlsFile, "pls-file", false, "Generate playlist file with .pls instead of .m3u")
 	flag.BoolVar(&argDisableLyrics, "disable-lyrics", false, "Disable download of songs lyrics and their application into mp3")
+	flag.BoolVar(&argFlushLyrics, "flush-lyrics", false, "Ignore lyrics cached from previous runs and query providers again")
+	flag.BoolVar(&argLyricsSidecar, "lyrics-sidecar", false, "Write time-stamped lyrics, if any, into .lrc files next to songs")
+	flag.BoolVar(&argAudioFeatures, "audio-features", false, "Enable Spotify audio features (BPM, key, energy, danceability, valence, acousticness) application into mp3")
+	flag.BoolVar(&argCamelot, "camelot", false, "Write musical key using Camelot notation instead of the standard one")
+	flag.BoolVar(&argFeaturingsTitle, "featurings-title", false, "Write featuring artists into title tag (as \"Title (ft. Artist)\") instead of into multi-value artist tag")
 	flag.BoolVar(&argDisableUpdateCheck, "disable-update-check", false, "Disable automatic update check at startup")
 	flag.BoolVar(&argDisableBrowserOpening, "disable-browser-opening", false, "Disable automatic browser opening for authentication")
 	flag.BoolVar(&argDisableIndexing, "disable-indexing", false, "Disable automatic library indexing (used to keep track of tracks names modifications)")
 	flag.BoolVar(&argAuthenticateOutside, "authenticate-outside", false, "Enable authentication flow to be handled outside this machine")
+	flag.BoolVar(&argAuthenticateHeadless, "authenticate-headless", false, "Enable authentication flow by pasting back the redirected URL, without any callback server")
+	flag.StringVar(&argUser, "user", "", "Spotify user ID whose stored session token should be used")
 	flag.BoolVar(&argInteractive, "interactive", false, "Enable interactive mode")
 	flag.BoolVar(&argInput, "input", false, "Always manually insert URL used for songs download")
+	flag.BoolVar(&argPrune, "prune", false, "Delete local songs which no synchronized media source is linking anymore")
+	flag.BoolVar(&argPruneDryRun, "prune-dry-run", false, "Only list local songs which would be pruned, without altering filesystem")
+	flag.StringVar(&argPruneQuarantine, "prune-quarantine", "", "Move pruned songs into given folder instead of deleting them")
 
 	// troubleshooting
 	flag.BoolVar(&argLog, "log", false, "Enable logging into file ./spotitube.log")
@@ -193,10 +256,37 @@ func mainFlagsParse() {
 	flag.BoolVar(&argDisableGui, "disable-gui", false, "Disable GUI to reduce noise and increase readability of program flow")
 	flag.Parse()
 
-	if !(argAlbums.IsSet() || argPlaylists.IsSet() || argTracksFix.IsSet()) {
+	for _, source := range argConsume.Entries {
+		if source == consumeLibrary {
+			argLibrary = true
+		} else if !contains(argPlaylists.Entries, source) {
+			argPlaylists.Entries = append(argPlaylists.Entries, source)
+		}
+	}
+
+	if !(argSavedAlbums || argFollowed || argAlbums.IsSet() || argArtists.IsSet() || argPlaylists.IsSet() ||
+		argTracks.IsSet() || argSearches.IsSet() || argFromFiles.IsSet() || argShows.IsSet() || argTracksFix.IsSet()) {
 		argLibrary = true
 	}
 
+	for _, expr := range append(argInclude.Entries, argExclude.Entries...) {
+		if _, err := regexp.Compile(expr); err != nil {
+			fmt.Println(fmt.Sprintf("Invalid filter expression: %s", err.Error()))
+			os.Exit(1)
+		}
+	}
+
+	if len(argShowSince) > 0 {
+		if _, err := time.Parse("2006-01-02", argShowSince); err != nil {
+			fmt.Println(fmt.Sprintf("Invalid show episodes date: %s", err.Error()))
+			os.Exit(1)
+		}
+	}
+
+	if !argArtistGroups.IsSet() {
+		argArtistGroups.Entries = []string{"album", "single"}
+	}
+
 	if argTracksFix.IsSet() {
 		for index, track := range argTracksFix.Entries {
 			trackAbs, err := filepath.Abs(track)
@@ -209,7 +299,46 @@ func mainFlagsParse() {
 		}
 	}
 
-	if argAuthenticateOutside {
+	for index, path := range argPush.Entries {
+		pathAbs, err := filepath.Abs(path)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		argPush.Entries[index] = pathAbs
+	}
+
+	for index, path := range argFromFiles.Entries {
+		pathAbs, err := filepath.Abs(path)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		argFromFiles.Entries[index] = pathAbs
+	}
+
+	if argPruneDryRun || len(argPruneQuarantine) > 0 {
+		argPrune = true
+	}
+
+	if argPrune && (argDisableIndexing || argTracksFix.IsSet()) {
+		fmt.Println("Pruning needs library indexing and cannot be used while fixing songs.")
+		os.Exit(1)
+	}
+
+	if len(argPruneQuarantine) > 0 {
+		quarantineAbs, err := filepath.Abs(argPruneQuarantine)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		argPruneQuarantine = quarantineAbs
+	}
+
+	if argAuthenticateOutside || argAuthenticateHeadless {
 		argDisableBrowserOpening = true
 	}
 }
@@ -229,6 +358,19 @@ func mainInit() {
 		os.Exit(0)
 	}
 
+	if argLogout {
+		user, err := sessionUser()
+		if err != nil {
+			fmt.Println("No stored session found.")
+			os.Exit(0)
+		}
+
+		os.Remove(fmt.Sprintf(usrGob, user, "token"))
+		os.Remove(usrSession)
+		fmt.Println(fmt.Sprintf("Session token for %s dropped.", user))
+		os.Exit(0)
+	}
+
 	// create configuration instance
 	var err error
 	cfg, err = config.Parse()
@@ -244,8 +386,44 @@ func mainInit() {
 		fmt.Println(fmt.Sprintf("Chosen music folder does not exist: %s", argFolder))
 		os.Exit(1)
 	}
+	// indexed paths have to be resolvable from within the music folder
+	if folderAbs, err := filepath.Abs(argFolder); err == nil {
+		argFolder = folderAbs
+	}
 	os.Chdir(argFolder)
 
+	if argMigrate {
+		var migrated int
+		filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+			// temporary songs are hidden
+			if info == nil || info.IsDir() || filepath.Ext(path) != ".mp3" || strings.HasPrefix(info.Name(), ".") {
+				return nil
+			}
+
+			if ok, err := track.MigrateTags(path); err != nil {
+				fmt.Println(fmt.Sprintf("Unable to migrate %s: %s", path, err.Error()))
+			} else if ok {
+				migrated++
+			}
+			return nil
+		})
+		fmt.Println(fmt.Sprintf("Migrated %d song(s).", migrated))
+		os.Exit(0)
+	}
+
+	// pushing playlists is not downloading anything
+	if !argPush.IsSet() {
+		downloader, err := shell.NewDownloader(cfg.Downloader.Backend, cfg.Downloader.Path, cfg.Downloader.Args)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		} else if !downloader.Exists() {
+			fmt.Println(fmt.Sprintf("%s command is not installed.", downloader.Name()))
+			os.Exit(1)
+		}
+		provider.Downloader = downloader
+	}
+
 	if !argDisableIndexing {
 		if system.FileExists(usrIndex) {
 			system.FetchGob(usrIndex, index)
@@ -253,6 +431,16 @@ func mainInit() {
 		index = track.Index(argFolder)
 	}
 
+	if argFlushLyrics {
+		lyricsCache = lyrics.NewCache()
+	} else {
+		lyricsCache = lyrics.OpenCache(usrLyrics)
+	}
+
+	if !argDisableLyrics {
+		lyricsChain = lyricsProviders()
+	}
+
 	for range [concurrencyLimit]int{} {
 		waitGroupPool <- true
 	}
@@ -286,7 +474,7 @@ func mainUI() {
 	})
 
 	ui.Append(fmt.Sprintf("%s %s", cui.Font("Folder:", cui.StyleBold), system.PrettyPath(argFolder)), cui.PanelLeftTop)
-	ui.Append(fmt.Sprintf("%s %s", cui.Font(fmt.Sprintf("%s version:", shell.YoutubeDL().Name()), cui.StyleBold), shell.YoutubeDL().Version()), cui.PanelLeftTop)
+	ui.Append(fmt.Sprintf("%s %s", cui.Font(fmt.Sprintf("%s version:", provider.Downloader.Name()), cui.StyleBold), provider.Downloader.Version()), cui.PanelLeftTop)
 	ui.Append(fmt.Sprintf("%s %s", cui.Font(fmt.Sprintf("%s version:", shell.FFmpeg().Name()), cui.StyleBold), shell.FFmpeg().Version()), cui.PanelLeftTop)
 	ui.Append(fmt.Sprintf("%s %d", cui.Font("Version:", cui.StyleBold), version), cui.PanelLeftBottom)
 	ui.Append(fmt.Sprintf("%s %s", cui.Font("Date:", cui.StyleBold), time.Now().Format("2006-01-02 15:04:05")), cui.PanelLeftBottom)
@@ -294,12 +482,39 @@ func mainUI() {
 }
 
 func mainAuthenticate() {
+	if token, err := fetchToken(); err == nil {
+		if c, err = spotify.Restore(token); err != nil {
+			ui.Append(fmt.Sprintf("Unable to restore session: %s", err.Error()), cui.WarningAppend)
+		}
+	}
+
+	if c == nil && argAuthenticateHeadless {
+		mainAuthenticateHeadless()
+	} else if c == nil {
+		mainAuthenticateBrowser()
+	}
+
+	c.Retries = cfg.Spotify.Retries
+	cUser, cUserID = c.User()
+	if err := dumpToken(); err != nil {
+		ui.Append(fmt.Sprintf("Unable to store session token: %s", err.Error()), cui.WarningAppend)
+	}
+
+	ui.Append("Authentication completed.")
+	ui.Append(fmt.Sprintf("%s %s", cui.Font("Session user:", cui.StyleBold), cUser), cui.PanelLeftTop)
+}
+
+func mainAuthenticateBrowser() {
 	host := "localhost"
 	if argAuthenticateOutside {
 		host = "spotitube.local"
 		ui.Prompt("Outside authentication enabled: assure \"spotitube.local\" points to this machine.")
 	}
 
+	if spotify.PKCE() {
+		ui.Append("No client secret set: authenticating through PKCE flow.", cui.DebugAppend)
+	}
+
 	uri := spotify.BuildAuthURL(host)
 	ui.Append(fmt.Sprintf("Authentication URL: %s", uri.Short), cui.ParagraphAutoReturn)
 	if !argDisableBrowserOpening {
@@ -310,16 +525,100 @@ func mainAuthenticate() {
 	if c, err = spotify.Auth(uri.Full, host, !argDisableBrowserOpening); err != nil {
 		ui.Prompt(fmt.Sprintf("Authentication failed: %s", err.Error()), cui.PromptExit)
 	}
+}
 
-	cUser, cUserID = c.User()
-	ui.Append("Authentication completed.")
-	ui.Append(fmt.Sprintf("%s %s", cui.Font("Session user:", cui.StyleBold), cUser), cui.PanelLeftTop)
+func mainAuthenticateHeadless() {
+	uri := spotify.BuildAuthURL("localhost")
+	if len(uri.Short) > 0 {
+		ui.Append(fmt.Sprintf("Authentication URL: %s", uri.Short), cui.ParagraphAutoReturn)
+	} else {
+		ui.Append(fmt.Sprintf("Authentication URL: %s", uri.Full), cui.ParagraphAutoReturn)
+	}
+	ui.Append("Open that URL on any machine: once logged in, the browser will fail to load the page it gets redirected to.")
+
+	var err error
+	input := ui.PromptInputMessage("Paste the URL you got redirected to (or its code)", cui.PromptInput)
+	if c, err = spotify.AuthCode(input); err != nil {
+		ui.Prompt(fmt.Sprintf("Authentication failed: %s", err.Error()), cui.PromptExit)
+	}
+}
+
+func mainPush() {
+	if !argPush.IsSet() {
+		return
+	}
+
+	var pushed int
+	for _, path := range argPush.Entries {
+		name, entries, err := track.ReadPlaylist(path)
+		if err != nil {
+			ui.Append(fmt.Sprintf("Unable to read playlist: %s", err.Error()), cui.WarningAppend)
+			continue
+		}
+
+		var (
+			ids        []spotify.ID
+			unresolved []string
+		)
+		for _, entry := range entries {
+			if id := track.GetTag(entry, track.ID3FrameSpotifyID); len(id) > 0 {
+				ids = append(ids, spotify.ID(id))
+			} else {
+				unresolved = append(unresolved, entry)
+			}
+		}
+
+		if len(unresolved) > 0 {
+			ui.Append(fmt.Sprintf("%d song(s) from %s have no Spotify ID and will be skipped:", len(unresolved), name), cui.WarningAppend)
+			for _, entry := range unresolved {
+				ui.Append(" - " + entry)
+			}
+		}
+
+		// pushing would drop from the remote playlist every song not resolved
+		if len(ids) == 0 {
+			ui.Append(fmt.Sprintf("No song from %s has a Spotify ID: playlist is not going to be pushed.", name), cui.WarningAppend)
+			continue
+		} else if len(unresolved) > len(ids) {
+			ui.Append(fmt.Sprintf("Most songs from %s have no Spotify ID: playlist is not going to be pushed.", name), cui.WarningAppend)
+			continue
+		}
+
+		if argSimulate {
+			ui.Append(fmt.Sprintf("I would like to push %d song(s) to %s playlist, but I'm just simulating.", len(ids), name))
+			continue
+		}
+
+		ui.Append(fmt.Sprintf("Pushing %d song(s) to %s playlist...", len(ids), name))
+		id, created, err := c.PushPlaylist(cUserID, name, ids)
+		if err != nil {
+			ui.Append(fmt.Sprintf("Unable to push playlist: %s", err.Error()), cui.WarningAppend)
+			continue
+		}
+
+		if created {
+			ui.Append(fmt.Sprintf("Playlist %s created: %s", name, id))
+		} else {
+			ui.Append(fmt.Sprintf("Playlist %s updated: %s", name, id))
+		}
+		pushed++
+	}
+
+	ui.Prompt(fmt.Sprintf("%d playlist(s) pushed to Spotify.", pushed), cui.PromptExit)
+	mainExit()
 }
 
 func mainFetch() {
 	mainFetchLibrary()
+	mainFetchSavedAlbums()
+	mainFetchFollowedPlaylists()
 	mainFetchAlbums()
+	mainFetchArtists()
 	mainFetchPlaylists()
+	mainFetchTracks()
+	mainFetchSearches()
+	mainFetchFiles()
+	mainFetchShows()
 	mainFetchTracksToFix()
 
 	if argFlushLocal || argFlushMetadata {
@@ -331,6 +630,8 @@ func mainFetch() {
 		}
 	}
 
+	mainFetchAudioFeatures()
+
 	ui.Append(fmt.Sprintf("%s %d", cui.Font("Songs online:", cui.StyleBold), len(tracks)), cui.PanelLeftTop)
 	ui.Append(fmt.Sprintf("%s %d", cui.Font("Songs offline:", cui.StyleBold), track.CountOffline(tracks)), cui.PanelLeftTop)
 	ui.Append(fmt.Sprintf("%s %d", cui.Font("Songs missing:", cui.StyleBold), track.CountOnline(tracks)), cui.PanelLeftTop)
@@ -349,21 +650,22 @@ func mainFetchLibrary() {
 	}
 
 	gob := fmt.Sprintf(usrGob, cUserID, "library")
-	if argFlushCache {
-		os.Remove(gob)
+	snapshot, err := c.LibrarySnapshot()
+	if err != nil {
+		ui.Append(fmt.Sprintf("Unable to check library for changes, refetching it: %s", err.Error()), cui.WarningAppend)
 	}
 
-	dump, dumpErr := fetchDump(gob)
-	if dumpErr == nil && time.Since(dump.Time) < cacheDuration {
+	dump, dumpErr := fetchDumpSnapshot(gob, snapshot)
+	if dumpErr == nil && !argFlushCache {
 		ui.Append(
-			fmt.Sprintf("%s %s / %s",
+			fmt.Sprintf("%s %s",
 				cui.Font("Library cache:", cui.StyleBold),
-				durafmt.ParseShort(time.Since(dump.Time)).String(),
-				durafmt.ParseShort(cacheDuration).String()),
+				cacheStatus(dump)),
 			cui.PanelLeftTop)
 		for _, t := range dump.Tracks {
 			tracksInflate(t)
 		}
+		consumable(consumeLibrary, dump.Tracks)
 		return
 	}
 
@@ -373,13 +675,61 @@ func mainFetchLibrary() {
 		ui.Prompt(fmt.Sprintf("Unable to fetch library: %s", err.Error()), cui.PromptExit)
 	}
 
-	if err := system.DumpGob(gob, track.TracksDump{Tracks: library, Time: time.Now()}); err != nil {
+	if err := system.DumpGob(gob, track.TracksDump{Tracks: library, Time: time.Now(), Snapshot: snapshot}); err != nil {
 		ui.Append(fmt.Sprintf("Unable to cache tracks: %s", err.Error()), cui.WarningAppend)
 	}
 
 	for _, t := range library {
 		tracksInflate(t)
 	}
+	consumable(consumeLibrary, library)
+}
+
+func mainFetchSavedAlbums() {
+	if !argSavedAlbums {
+		return
+	}
+
+	ui.Append("Fetching saved albums...")
+	albums, err := c.SavedAlbums()
+	if err != nil {
+		ui.Prompt(fmt.Sprintf("Unable to fetch saved albums: %s", err.Error()), cui.PromptExit)
+	}
+
+	for _, a := range albums {
+		var artists []string
+		for _, artist := range a.Artists {
+			artists = append(artists, artist.Name)
+		}
+
+		if sourceFiltered(a.Name, artists...) {
+			ui.Append(fmt.Sprintf("Album %s filtered out.", a.Name), cui.DebugAppend)
+			continue
+		}
+
+		argAlbums.Entries = append(argAlbums.Entries, string(a.URI))
+	}
+}
+
+func mainFetchFollowedPlaylists() {
+	if !argFollowed {
+		return
+	}
+
+	ui.Append("Fetching followed playlists...")
+	followed, err := c.FollowedPlaylists()
+	if err != nil {
+		ui.Prompt(fmt.Sprintf("Unable to fetch followed playlists: %s", err.Error()), cui.PromptExit)
+	}
+
+	for _, p := range followed {
+		if sourceFiltered(p.Name, p.Owner.DisplayName, p.Owner.ID) {
+			ui.Append(fmt.Sprintf("Playlist %s filtered out.", p.Name), cui.DebugAppend)
+			continue
+		}
+
+		argPlaylists.Entries = append(argPlaylists.Entries, string(p.URI))
+	}
 }
 
 func mainFetchAlbums() {
@@ -393,19 +743,23 @@ func mainFetchAlbums() {
 			uri = alias
 		}
 
+		if _, err := spotify.ParseResourceOf(uri, spotify.ResourceAlbum); err != nil {
+			ui.Prompt(fmt.Sprintf("Invalid album reference: %s", err.Error()), cui.PromptExit)
+		}
+
 		gob := fmt.Sprintf(usrGob, cUserID, fmt.Sprintf("album_%s", spotify.IDFromURI(uri)))
-		if argFlushCache {
-			os.Remove(gob)
+		snapshot, err := c.AlbumSnapshot(uri)
+		if err != nil {
+			ui.Append(fmt.Sprintf("Unable to check album for changes, refetching it: %s", err.Error()), cui.WarningAppend)
 		}
 
-		dump, dumpErr := fetchDump(gob)
-		if dumpErr == nil && time.Since(dump.Time) < cacheDuration {
+		dump, dumpErr := fetchDumpSnapshot(gob, snapshot)
+		if dumpErr == nil && !argFlushCache {
 			ui.Append(
-				fmt.Sprintf("%s %s / %s",
+				fmt.Sprintf("%s %s",
 					cui.Font(fmt.Sprintf("%s cache:",
 						spotify.IDFromURI(uri)), cui.StyleBold),
-					durafmt.ParseShort(time.Since(dump.Time)).String(),
-					durafmt.ParseShort(cacheDuration).String()),
+					cacheStatus(dump)),
 				cui.PanelLeftTop)
 			for _, t := range dump.Tracks {
 				tracksInflate(t)
@@ -419,7 +773,7 @@ func mainFetchAlbums() {
 			ui.Prompt(fmt.Sprintf("Unable to fetch album: %s", err.Error()), cui.PromptExit)
 		}
 
-		if err := system.DumpGob(gob, track.TracksDump{Tracks: album, Time: time.Now()}); err != nil {
+		if err := system.DumpGob(gob, track.TracksDump{Tracks: album, Time: time.Now(), Snapshot: snapshot}); err != nil {
 			ui.Append(fmt.Sprintf("Unable to cache tracks: %s", err.Error()), cui.WarningAppend)
 		}
 
@@ -429,6 +783,62 @@ func mainFetchAlbums() {
 	}
 }
 
+func mainFetchArtists() {
+	if !argArtists.IsSet() {
+		return
+	}
+
+	for _, uri := range argArtists.Entries {
+		alias := cfg.URI(uri)
+		if len(alias) > 0 {
+			uri = alias
+		}
+
+		if _, err := spotify.ParseResourceOf(uri, spotify.ResourceArtist); err != nil {
+			ui.Prompt(fmt.Sprintf("Invalid artist reference: %s", err.Error()), cui.PromptExit)
+		}
+
+		name := string(spotify.IDFromURI(uri))
+		if a, err := c.Artist(uri); err == nil {
+			name = a.Name
+		}
+
+		gob := fmt.Sprintf(usrGob, cUserID, fmt.Sprintf("artist_%s_%s",
+			spotify.IDFromURI(uri), strings.Join(argArtistGroups.Entries, "-")))
+		snapshot, err := c.ArtistSnapshot(uri, argArtistGroups.Entries...)
+		if err != nil {
+			ui.Append(fmt.Sprintf("Unable to check artist for changes, refetching it: %s", err.Error()), cui.WarningAppend)
+		}
+
+		dump, dumpErr := fetchDumpSnapshot(gob, snapshot)
+		if dumpErr == nil && !argFlushCache {
+			ui.Append(
+				fmt.Sprintf("%s %s",
+					cui.Font(fmt.Sprintf("%s cache:", name), cui.StyleBold),
+					cacheStatus(dump)),
+				cui.PanelLeftTop)
+			for _, t := range dump.Tracks {
+				tracksInflate(t)
+			}
+			continue
+		}
+
+		ui.Append(fmt.Sprintf("Fetching artist %s discography...", name))
+		artist, err := c.ArtistTracks(uri, argArtistGroups.Entries...)
+		if err != nil {
+			ui.Prompt(fmt.Sprintf("Unable to fetch artist: %s", err.Error()), cui.PromptExit)
+		}
+
+		if err := system.DumpGob(gob, track.TracksDump{Tracks: artist, Time: time.Now(), Snapshot: snapshot}); err != nil {
+			ui.Append(fmt.Sprintf("Unable to cache tracks: %s", err.Error()), cui.WarningAppend)
+		}
+
+		for _, t := range artist {
+			tracksInflate(t)
+		}
+	}
+}
+
 func mainFetchPlaylists() {
 	if !argPlaylists.IsSet() {
 		return
@@ -440,30 +850,36 @@ func mainFetchPlaylists() {
 			uri = alias
 		}
 
-		playlist := &track.Playlist{}
+		if _, err := spotify.ParseResourceOf(uri, spotify.ResourcePlaylist); err != nil {
+			ui.Prompt(fmt.Sprintf("Invalid playlist reference: %s", err.Error()), cui.PromptExit)
+		}
+
+		var (
+			playlist = &track.Playlist{}
+			snapshot string
+		)
 		if p, err := c.Playlist(uri); err == nil {
 			playlist.Name = p.Name
 			playlist.Owner = p.Owner.DisplayName
+			snapshot = p.SnapshotID
+		} else {
+			ui.Append(fmt.Sprintf("Unable to check playlist for changes, refetching it: %s", err.Error()), cui.WarningAppend)
 		}
 
 		gob := fmt.Sprintf(usrGob, cUserID, fmt.Sprintf("playlist_%s", spotify.IDFromURI(uri)))
-		if argFlushCache {
-			os.Remove(gob)
-		}
-
-		dump, dumpErr := fetchDump(gob)
-		if dumpErr == nil && time.Since(dump.Time) < cacheDuration {
+		dump, dumpErr := fetchDumpSnapshot(gob, snapshot)
+		if dumpErr == nil && !argFlushCache {
 			ui.Append(
-				fmt.Sprintf("%s %s / %s",
+				fmt.Sprintf("%s %s",
 					cui.Font(fmt.Sprintf("%s cache:", playlist.Name), cui.StyleBold),
-					durafmt.ParseShort(time.Since(dump.Time)).String(),
-					durafmt.ParseShort(cacheDuration).String()),
+					cacheStatus(dump)),
 				cui.PanelLeftTop)
 			for _, t := range dump.Tracks {
 				tracksInflate(t)
 			}
 			playlist.Tracks = dump.Tracks
 			playlists = append(playlists, playlist)
+			consumable(uri, dump.Tracks)
 			continue
 		}
 
@@ -473,7 +889,7 @@ func mainFetchPlaylists() {
 			ui.Prompt(fmt.Sprintf("Unable to fetch playlist: %s", err.Error()), cui.PromptExit)
 		}
 
-		if err := system.DumpGob(gob, track.TracksDump{Tracks: p, Time: time.Now()}); err != nil {
+		if err := system.DumpGob(gob, track.TracksDump{Tracks: p, Time: time.Now(), Snapshot: snapshot}); err != nil {
 			ui.Append(fmt.Sprintf("Unable to cache tracks: %s", err.Error()), cui.WarningAppend)
 		}
 
@@ -483,140 +899,469 @@ func mainFetchPlaylists() {
 
 		playlist.Tracks = p
 		playlists = append(playlists, playlist)
+		consumable(uri, p)
 	}
 }
 
-func mainFetchTracksToFix() {
-	if !argTracksFix.IsSet() {
+func mainFetchTracks() {
+	if !argTracks.IsSet() {
 		return
 	}
 
-	ui.Append(fmt.Sprintf("%s %d", cui.Font("Fix song(s):", cui.StyleBold), len(argTracksFix.Entries)), cui.PanelLeftTop)
-	for _, tFix := range argTracksFix.Entries {
-		if t, err := track.OpenLocalTrack(tFix); err != nil {
-			ui.Prompt(fmt.Sprintf("Something went wrong: %s", err.Error()))
-		} else {
-			tracksInflateWithOption(t, track.SyncOptionsFlush())
+	for _, uri := range argTracks.Entries {
+		if _, err := spotify.ParseResourceOf(uri, spotify.ResourceTrack); err != nil {
+			ui.Prompt(fmt.Sprintf("Invalid track reference: %s", err.Error()), cui.PromptExit)
 		}
 	}
-}
 
-func mainSearch() {
-	ctr := 0
-	songsFetch, songsFlush, songsIgnore := countSongs()
+	ui.Append(fmt.Sprintf("Fetching %d track(s)...", len(argTracks.Entries)))
+	tracks, err := c.TracksFromURIs(argTracks.Entries...)
+	if err != nil {
+		ui.Prompt(fmt.Sprintf("Unable to fetch tracks: %s", err.Error()), cui.PromptExit)
+	}
 
-	ui.ProgressMax = len(tracks)
-	ui.Append(fmt.Sprintf("%d will be downloaded, %d flushed and %d ignored", songsFetch, songsFlush, songsIgnore))
+	for _, t := range tracks {
+		tracksInflate(t)
+	}
+}
 
-	for track, trackOpts := range tracks {
-		ctr++
-		ui.ProgressIncrease()
-		ui.Append(fmt.Sprintf("%d/%d: \"%s\"", ctr, len(tracks), track.Basename()), cui.StyleBold)
+func mainFetchSearches() {
+	if !argSearches.IsSet() {
+		return
+	}
 
-		// rename local file if Spotify has renamed it
-		if path, match, err := index.Match(track.SpotifyID, track.Filename()); err == nil && !match {
-			ui.Append(fmt.Sprintf("Track %s has been renamed: moving to %s", track.Filename(), path))
-			if err := os.Rename(path, track.Filename()); err != nil {
-				ui.Append(fmt.Sprintf("Unable to rename: %s", err.Error()), cui.ErrorAppend)
-			} else {
-				index.Rename(track.SpotifyID, track.Filename())
-				if !argFlushLocal {
-					trackOpts.Source = false
-				}
-			}
+	for _, query := range argSearches.Entries {
+		ui.Append(fmt.Sprintf("Searching \"%s\" on Spotify...", query))
+		candidates, err := c.SearchTracks(query)
+		if err != nil {
+			ui.Append(fmt.Sprintf("Unable to search \"%s\": %s", query, err.Error()), cui.WarningAppend)
+			sourcesFail = true
+			continue
 		}
 
-		if !track.Local() || trackOpts.Source || argSimulate {
-			entry := new(provider.Entry)
-
-			for _, p := range provider.All() {
-				ui.Append(fmt.Sprintf("Searching entries on %s provider", p.Name()), cui.DebugAppend)
-
-				var (
-					provEntries = []*provider.Entry{}
-					provErr     error
-					entryPick   bool
-				)
-
-				if !argInput {
-					provEntries, provErr = p.Query(track)
-					if provErr != nil {
-						ui.Append(
-							fmt.Sprintf("Unable to search %s on %s provider: %s.", track.Basename(), p.Name(), provErr.Error()),
-							cui.WarningAppend)
-						continue
-					}
-
-					for _, provEntry := range provEntries {
-						ui.Append(
-							fmt.Sprintf("Result met: ID: %s,\nTitle: %s,\nUser: %s,\nDuration: %d.",
-								provEntry.ID, provEntry.Title, provEntry.User, provEntry.Duration),
-							cui.DebugAppend)
-
-						entryPick = bool(p.Match(provEntry, track) == nil)
-						if argInteractive {
-							entryPick = ui.Prompt(
-								fmt.Sprintf(
-									"Track: %s\n\nID: %s\nTitle: %s\nUser: %s\nDuration: %d\nURL: %s\nResult is matching: %s",
-									track.Basename(), provEntry.ID, provEntry.Title, provEntry.User,
-									provEntry.Duration, provEntry.URL, strconv.FormatBool(entryPick)),
-								cui.PromptBinary)
-						}
-
-						if entryPick {
-							ui.Append(fmt.Sprintf("Video \"%s\" is good to go for \"%s\".", provEntry.Title, track.Basename()))
-							entry = provEntry
-							break
-						}
-					}
-				}
-
-				if argInput && entry.Empty() {
-					if url := ui.PromptInputMessage(fmt.Sprintf("Enter URL for \"%s\"", track.Basename()), cui.PromptInput); len(url) > 0 {
-						if err := p.Support(url); err == nil {
-							entry.URL = url
-						} else {
-							ui.Prompt(fmt.Sprintf("Something went wrong: %s", err.Error()))
-						}
-					}
-				}
+		if pick := searchPick(query, candidates); pick != nil {
+			for _, t := range c.ParseTracks([]*spotify.Track{pick}) {
+				tracksInflate(t)
+			}
+		} else {
+			ui.Append(fmt.Sprintf("No track picked for \"%s\".", query), cui.WarningAppend)
+			sourcesFail = true
+		}
+	}
+}
 
-				if entry.Empty() {
-					ui.Append("No entry to download has been found.", cui.ErrorAppend)
-					tracksFailed = append(tracksFailed, track)
-					continue
-				}
+func mainFetchFiles() {
+	if !argFromFiles.IsSet() {
+		return
+	}
 
-				if argSimulate {
-					ui.Append(fmt.Sprintf("I would like to download \"%s\" for \"%s\" track, but I'm just simulating.", entry.Repr(), track.Basename()))
-					continue
-				}
+	for _, path := range argFromFiles.Entries {
+		var (
+			playlist = &track.Playlist{Name: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), Owner: cUser}
+			gob      = fmt.Sprintf(usrGob, cUserID, fmt.Sprintf("file_%s", slug.Make(path)))
+		)
+		snapshot, err := fileSnapshot(path)
+		if err != nil {
+			ui.Append(fmt.Sprintf("Unable to check %s for changes, refetching it: %s", path, err.Error()), cui.WarningAppend)
+		}
 
-				if trackOpts.Source && track.URL == entry.URL {
-					ui.Append("Downloaded track is still the best result I can find.")
-					ui.Append(fmt.Sprintf("Local track origin URL %s is the same as the chosen one %s.", track.URL, entry.URL), cui.DebugAppend)
-					continue
-				}
+		dump, dumpErr := fetchDumpSnapshot(gob, snapshot)
+		if dumpErr == nil && !argFlushCache {
+			ui.Append(
+				fmt.Sprintf("%s %s",
+					cui.Font(fmt.Sprintf("%s cache:", playlist.Name), cui.StyleBold),
+					cacheStatus(dump)),
+				cui.PanelLeftTop)
+			for _, t := range dump.Tracks {
+				tracksInflate(t)
 			}
-
-			if entry.Empty() || track.URL == entry.URL {
+			playlist.Tracks = dump.Tracks
+			playlists = append(playlists, playlist)
+			continue
+		}
+
+		entries, err := importer.Parse(path)
+		if err != nil {
+			ui.Prompt(fmt.Sprintf("Unable to import %s: %s", path, err.Error()), cui.PromptExit)
+		}
+
+		ui.Append(fmt.Sprintf("Resolving %d %s entries from %s...", len(entries), importer.For(path).Name(), filepath.Base(path)))
+		var ids []spotify.ID
+		for _, entry := range entries {
+			if len(entry.URI) > 0 {
+				ids = append(ids, spotify.IDFromURI(entry.URI))
+			}
+		}
+
+		byID := make(map[spotify.ID]*spotify.Track)
+		if tracksByID, err := c.Tracks(ids); err == nil {
+			for _, t := range tracksByID {
+				byID[t.ID] = t
+			}
+		}
+
+		var (
+			resolved   []*spotify.Track
+			unresolved []importer.Entry
+		)
+		for _, entry := range entries {
+			if t, ok := byID[spotify.IDFromURI(entry.URI)]; ok && len(entry.URI) > 0 {
+				resolved = append(resolved, t)
 				continue
 			}
 
-			ui.Append(fmt.Sprintf("Going to download %s...", entry.URL))
-			p, err := provider.For(entry.URL)
+			candidates, err := c.SearchTracks(entry.Query())
 			if err != nil {
-				ui.Append(fmt.Sprintf("Unable to reconstruct provider for \"%s\"", entry.URL), cui.ErrorAppend)
-				continue
+				ui.Append(fmt.Sprintf("Unable to search \"%s\": %s", entry.Query(), err.Error()), cui.DebugAppend)
 			}
 
-			if err := p.Download(entry, track.FilenameTemporary()); err != nil {
-				ui.Append(fmt.Sprintf("Something went wrong downloading \"%s\": %s.", track.Basename(), err.Error()), cui.WarningAppend)
-				tracksFailed = append(tracksFailed, track)
-				continue
+			if pick := searchPick(entry.Query(), candidates); pick != nil {
+				resolved = append(resolved, pick)
+			} else {
+				unresolved = append(unresolved, entry)
+			}
+		}
+
+		ui.Append(fmt.Sprintf("%d/%d entries of %s resolved.", len(resolved), len(entries), filepath.Base(path)))
+		for _, entry := range unresolved {
+			ui.Append(fmt.Sprintf(" - unresolved: %s", entry.Query()), cui.WarningAppend)
+		}
+		if len(unresolved) > 0 {
+			sourcesFail = true
+		}
+
+		playlist.Tracks = c.ParseTracks(resolved)
+		if err := system.DumpGob(gob, track.TracksDump{Tracks: playlist.Tracks, Time: time.Now(), Snapshot: snapshot}); err != nil {
+			ui.Append(fmt.Sprintf("Unable to cache tracks: %s", err.Error()), cui.WarningAppend)
+		}
+
+		for _, t := range playlist.Tracks {
+			tracksInflate(t)
+		}
+		playlists = append(playlists, playlist)
+	}
+}
+
+func mainFetchShows() {
+	if !argShows.IsSet() {
+		return
+	}
+
+	var since time.Time
+	if len(argShowSince) > 0 {
+		since, _ = time.Parse("2006-01-02", argShowSince)
+	}
+
+	for _, uri := range argShows.Entries {
+		alias := cfg.URI(uri)
+		if len(alias) > 0 {
+			uri = alias
+		}
+
+		if _, err := spotify.ParseResourceOf(uri, spotify.ResourceShow); err != nil {
+			ui.Prompt(fmt.Sprintf("Invalid show reference: %s", err.Error()), cui.PromptExit)
+		}
+
+		show, err := c.Show(uri)
+		if err != nil {
+			ui.Prompt(fmt.Sprintf("Unable to fetch show: %s", err.Error()), cui.PromptExit)
+		}
+
+		playlist := &track.Playlist{
+			Name:   show.Name,
+			Owner:  show.Publisher,
+			Folder: track.Sanitize(show.Name),
+		}
+
+		gob := fmt.Sprintf(usrGob, cUserID, fmt.Sprintf("show_%s_%d_%s",
+			spotify.IDFromURI(uri), argShowEpisodes, argShowSince))
+		snapshot, err := c.ShowSnapshot(show)
+		if err != nil {
+			ui.Append(fmt.Sprintf("Unable to check show for changes, refetching it: %s", err.Error()), cui.WarningAppend)
+		}
+
+		dump, dumpErr := fetchDumpSnapshot(gob, snapshot)
+		if dumpErr == nil && !argFlushCache {
+			ui.Append(
+				fmt.Sprintf("%s %s",
+					cui.Font(fmt.Sprintf("%s cache:", show.Name), cui.StyleBold),
+					cacheStatus(dump)),
+				cui.PanelLeftTop)
+			for _, t := range dump.Tracks {
+				tracksInflate(t)
+			}
+			playlist.Tracks = dump.Tracks
+			playlists = append(playlists, playlist)
+			continue
+		}
+
+		ui.Append(fmt.Sprintf("Fetching show %s episodes...", show.Name))
+		episodes, err := c.ShowEpisodes(show, argShowEpisodes, since)
+		if err != nil {
+			ui.Prompt(fmt.Sprintf("Unable to fetch show episodes: %s", err.Error()), cui.PromptExit)
+		}
+
+		if err := system.DumpGob(gob, track.TracksDump{Tracks: episodes, Time: time.Now(), Snapshot: snapshot}); err != nil {
+			ui.Append(fmt.Sprintf("Unable to cache tracks: %s", err.Error()), cui.WarningAppend)
+		}
+
+		for _, t := range episodes {
+			tracksInflate(t)
+		}
+
+		playlist.Tracks = episodes
+		playlists = append(playlists, playlist)
+	}
+}
+
+func mainFetchAudioFeatures() {
+	if !argAudioFeatures {
+		return
+	}
+
+	// only songs whose metadata are going to be flushed need them
+	var ids []spotify.ID
+	for t, opts := range tracks {
+		if len(t.SpotifyID) > 0 && (!t.Local() || opts.Metadata) {
+			ids = append(ids, spotify.ID(t.SpotifyID))
+		}
+	}
+
+	if len(ids) == 0 {
+		return
+	}
+
+	ui.Append(fmt.Sprintf("Fetching audio features for %d song(s)...", len(ids)))
+	features, err := c.AudioFeatures(ids)
+	if err != nil {
+		ui.Append(fmt.Sprintf("Unable to fetch audio features: %s", err.Error()), cui.WarningAppend)
+	}
+
+	for t := range tracks {
+		if f, ok := features[spotify.ID(t.SpotifyID)]; ok {
+			t.SetAudioFeatures(f, argCamelot)
+		}
+	}
+}
+
+func mainFetchTracksToFix() {
+	if !argTracksFix.IsSet() {
+		return
+	}
+
+	ui.Append(fmt.Sprintf("%s %d", cui.Font("Fix song(s):", cui.StyleBold), len(argTracksFix.Entries)), cui.PanelLeftTop)
+	for _, tFix := range argTracksFix.Entries {
+		if t, err := track.OpenLocalTrack(tFix); err != nil {
+			ui.Prompt(fmt.Sprintf("Something went wrong: %s", err.Error()))
+		} else {
+			tracksInflateWithOption(t, track.SyncOptionsFlush())
+		}
+	}
+}
+
+func mainPrune() {
+	if !argPrune {
+		return
+	}
+
+	if sourcesFail {
+		ui.Append("Some media source has not been completely fetched: no song is going to be pruned.", cui.WarningAppend)
+		return
+	}
+
+	linkedIDs, linkedPaths, err := sourcesLinked()
+	if err != nil {
+		ui.Append(fmt.Sprintf("Unable to read media sources cache: %s. No song is going to be pruned.", err.Error()), cui.WarningAppend)
+		return
+	}
+
+	// only songs which no media source is linking, neither the ones
+	// synchronized now nor the ones cached from previous runs, are orphans
+	orphans := make(map[string]string)
+	for id, path := range index.Tracks {
+		if linkedIDs[id] {
+			continue
+		}
+
+		if pathAbs, err := filepath.Abs(path); err != nil || linkedPaths[pathAbs] {
+			continue
+		}
+
+		orphans[id] = path
+	}
+
+	if len(orphans) == 0 {
+		ui.Append("No local song needs to be pruned.")
+		return
+	}
+
+	var paths []string
+	for _, path := range orphans {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	ui.Append(fmt.Sprintf("%d local song(s) are not part of any media source anymore:", len(orphans)))
+	for _, path := range paths {
+		ui.Append(" - " + path)
+	}
+
+	if argPruneDryRun || argSimulate {
+		ui.Append("Dry run: no song is going to be pruned.")
+		return
+	}
+
+	action := "deleted"
+	if len(argPruneQuarantine) > 0 {
+		action = fmt.Sprintf("moved to %s", argPruneQuarantine)
+	}
+	if !ui.Prompt(fmt.Sprintf("Are you sure %d song(s) should be %s?", len(orphans), action), cui.PromptBinary) {
+		ui.Append("Pruning cancelled.")
+		return
+	}
+
+	var pruned = make(map[string]bool)
+	for id, path := range orphans {
+		if err := prune(path); err != nil {
+			ui.Append(fmt.Sprintf("Unable to prune %s: %s", path, err.Error()), cui.WarningAppend)
+			continue
+		}
+
+		index.Remove(id)
+		if pathAbs, err := filepath.Abs(path); err == nil {
+			pruned[pathAbs] = true
+		}
+	}
+	ui.Append(fmt.Sprintf("%d song(s) pruned.", len(pruned)))
+
+	// playlist files are not regenerated for the sources left out of this run
+	filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+		if info == nil || info.IsDir() || !(strings.EqualFold(filepath.Ext(path), ".m3u") || strings.EqualFold(filepath.Ext(path), ".pls")) {
+			return nil
+		}
+
+		if stripped, err := track.StripPlaylist(path, pruned); err != nil {
+			ui.Append(fmt.Sprintf("Unable to update playlist %s: %s", path, err.Error()), cui.WarningAppend)
+		} else if stripped > 0 {
+			ui.Append(fmt.Sprintf("%d pruned song(s) removed from playlist %s.", stripped, path), cui.DebugAppend)
+		}
+		return nil
+	})
+}
+
+// sourcesLinked returns the Spotify IDs and absolute paths of the songs linked
+// by the media sources synchronized in this run and by every cached one, of any user
+func sourcesLinked() (map[string]bool, map[string]bool, error) {
+	var (
+		ids   = make(map[string]bool)
+		paths = make(map[string]bool)
+	)
+	for id := range sourcesIDs {
+		ids[id] = true
+	}
+	for path := range sourcesPaths {
+		paths[path] = true
+	}
+
+	gobs, err := filepath.Glob(fmt.Sprintf(usrGob, "*", "*"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, gob := range gobs {
+		// session tokens are the only cached objects not being tracks
+		if strings.HasSuffix(gob, "_token.gob") {
+			continue
+		}
+
+		var dump track.TracksDump
+		if err := system.FetchGob(gob, &dump); err != nil {
+			return nil, nil, fmt.Errorf("%s: %s", filepath.Base(gob), err.Error())
+		}
+
+		for _, t := range dump.Tracks {
+			ids[t.SpotifyID] = true
+			if path, err := filepath.Abs(t.Filename()); err == nil {
+				paths[path] = true
+			}
+		}
+	}
+
+	return ids, paths, nil
+}
+
+// prune deletes or quarantines the song at given path,
+// along with its synced lyrics sidecar, if any
+func prune(path string) error {
+	if err := pruneFile(path); err != nil {
+		return err
+	}
+
+	if sidecar := track.LyricsFilename(path); system.FileExists(sidecar) {
+		return pruneFile(sidecar)
+	}
+	return nil
+}
+
+func pruneFile(path string) error {
+	if len(argPruneQuarantine) == 0 {
+		return os.Remove(path)
+	}
+
+	// keep the song path, relative to the music folder, into the quarantine
+	dest := filepath.Join(argPruneQuarantine, filepath.Base(path))
+	if wd, err := os.Getwd(); err == nil {
+		if pathAbs, err := filepath.Abs(path); err == nil {
+			if rel, err := filepath.Rel(wd, pathAbs); err == nil && !strings.HasPrefix(rel, "..") {
+				dest = filepath.Join(argPruneQuarantine, rel)
+			}
+		}
+	}
+
+	if err := system.Mkdir(filepath.Dir(dest)); err != nil {
+		return err
+	}
+
+	if err := os.Rename(path, dest); err != nil {
+		// quarantine could be on a different filesystem
+		return system.FileMove(path, dest)
+	}
+
+	return nil
+}
+
+func mainSearch() {
+	ctr := 0
+	songsFetch, songsFlush, songsIgnore := countSongs()
+
+	ui.ProgressMax = len(tracks)
+	ui.Append(fmt.Sprintf("%d will be downloaded, %d flushed and %d ignored", songsFetch, songsFlush, songsIgnore))
+
+	for track, trackOpts := range tracks {
+		ctr++
+		ui.ProgressIncrease()
+		ui.Append(fmt.Sprintf("%d/%d: \"%s\"", ctr, len(tracks), track.Basename()), cui.StyleBold)
+
+		// rename local file if Spotify has renamed it
+		if path, match, err := index.Match(track.SpotifyID, track.Filename()); err == nil && !match {
+			ui.Append(fmt.Sprintf("Track %s has been renamed: moving to %s", track.Filename(), path))
+			if len(track.Folder) > 0 {
+				system.Mkdir(track.Folder)
+			}
+			if err := songRename(track, path); err != nil {
+				ui.Append(fmt.Sprintf("Unable to rename: %s", err.Error()), cui.ErrorAppend)
+			} else {
+				index.Rename(track.SpotifyID, track.Filename())
+				if !argFlushLocal {
+					trackOpts.Source = false
+				}
 			}
+		}
 
-			track.URL = entry.URL
+		if !track.Local() || trackOpts.Source || argSimulate {
+			if !songDownload(track, trackOpts) {
+				continue
+			}
 		}
 
 		if track.Local() && !trackOpts.Metadata {
@@ -642,8 +1387,10 @@ func mainSearch() {
 	waitGroup.Wait()
 
 	flushPlaylists()
+	consume()
 
 	index.Sync(usrIndex)
+	lyricsCache.Sync(usrLyrics)
 
 	close(waitGroupPool)
 	waitGroup.Wait()
@@ -665,6 +1412,10 @@ func mainSearch() {
 func mainExit(delay ...time.Duration) {
 	system.FileWildcardDelete(argFolder, track.JunkWildcards()...)
 
+	if c != nil {
+		dumpToken()
+	}
+
 	if len(delay) > 0 {
 		time.Sleep(delay[0])
 	}
@@ -681,36 +1432,198 @@ func tracksInflate(t *track.Track) {
 }
 
 func tracksInflateWithOption(t *track.Track, opts *track.SyncOptions) {
+	// duplicates still keep their local files from being pruned
+	sourcesIDs[t.SpotifyID] = true
+	if path, err := filepath.Abs(t.Filename()); err == nil {
+		sourcesPaths[path] = true
+	}
+
 	var indexKey = t.FilenameTemporary()
 	if _, isDup := tracksIndex[indexKey]; isDup {
 		return
 	}
 
+	opts.FeaturingsTitle = argFeaturingsTitle
 	tracks[t] = opts
 	tracksIndex[indexKey] = 1
 }
 
+// songDownload looks for an entry for given track and downloads it,
+// returning whether the track is worth to be processed further
+func songDownload(t *track.Track, opts *track.SyncOptions) bool {
+	entry := new(provider.Entry)
+	if !argInput {
+		if pick := songSearch(t); pick != nil {
+			entry = pick
+		}
+	} else if url := ui.PromptInputMessage(fmt.Sprintf("Enter URL for \"%s\"", t.Basename()), cui.PromptInput); len(url) > 0 {
+		if _, err := provider.For(url); err == nil {
+			entry.URL = url
+		} else {
+			ui.Prompt(fmt.Sprintf("Something went wrong: %s", err.Error()))
+		}
+	}
+
+	if entry.Empty() {
+		ui.Append("No entry to download has been found.", cui.ErrorAppend)
+		trackFailed(t)
+		return false
+	}
+
+	if argSimulate {
+		ui.Append(fmt.Sprintf("I would like to download \"%s\" for \"%s\" track, but I'm just simulating.", entry.Repr(), t.Basename()))
+		return false
+	}
+
+	// providers point to the same video using different URLs
+	if id := provider.IDFromURL(t.URL); len(id) > 0 && id == provider.IDFromURL(entry.URL) {
+		if opts.Source {
+			ui.Append("Downloaded track is still the best result I can find.")
+			ui.Append(fmt.Sprintf("Local track origin URL %s is the same as the chosen one %s.", t.URL, entry.URL), cui.DebugAppend)
+		}
+		return false
+	}
+
+	ui.Append(fmt.Sprintf("Going to download %s...", entry.URL))
+	p, err := provider.For(entry.URL)
+	if err != nil {
+		ui.Append(fmt.Sprintf("Unable to reconstruct provider for \"%s\"", entry.URL), cui.ErrorAppend)
+		return false
+	}
+
+	if err := p.Download(entry, t.FilenameTemporary()); err != nil {
+		ui.Append(fmt.Sprintf("Something went wrong downloading \"%s\": %s.", t.Basename(), err.Error()), cui.WarningAppend)
+		trackFailed(t)
+		return false
+	}
+
+	t.URL = entry.URL
+	return true
+}
+
+// songSearch gathers entries for given track from all the providers
+// and returns the best matching one, or the one picked by the user
+// among the best ones if interactive
+func songSearch(t *track.Track) *provider.Entry {
+	candidates, errs := provider.Search(t)
+	for name, err := range errs {
+		ui.Append(
+			fmt.Sprintf("Unable to search %s on %s provider: %s.", t.Basename(), name, err.Error()),
+			cui.WarningAppend)
+	}
+
+	shortlist := candidates
+	if len(shortlist) > provider.ShortlistSize {
+		shortlist = shortlist[:provider.ShortlistSize]
+	}
+
+	for _, candidate := range shortlist {
+		ui.Append(
+			fmt.Sprintf("Result met on %s: ID: %s,\nTitle: %s,\nUser: %s,\nAlbum: %s,\nDuration: %d,\nOfficial: %s,\nScore: %d.",
+				candidate.Provider.Name(), candidate.ID, candidate.Title, candidate.User, candidate.Album,
+				candidate.Duration, strconv.FormatBool(candidate.Official), candidate.Score),
+			cui.DebugAppend)
+	}
+
+	// the user is only bothered with the best candidates
+	if argInteractive {
+		candidates = shortlist
+	}
+
+	for _, candidate := range candidates {
+		entryPick := candidate.Match == nil
+		if argInteractive {
+			entryPick = ui.Prompt(
+				fmt.Sprintf(
+					"Track: %s\n\nProvider: %s\nID: %s\nTitle: %s\nUser: %s\nDuration: %d\nURL: %s\nScore: %d\nResult is matching: %s",
+					t.Basename(), candidate.Provider.Name(), candidate.ID, candidate.Title, candidate.User,
+					candidate.Duration, candidate.URL, candidate.Score, strconv.FormatBool(candidate.Match == nil)),
+				cui.PromptBinary)
+		}
+
+		if entryPick {
+			ui.Append(fmt.Sprintf("Video \"%s\" is good to go for \"%s\".", candidate.Title, t.Basename()))
+			return candidate.Entry
+		}
+	}
+
+	return nil
+}
+
 func songFetchLyrics(t *track.Track) error {
 	if argDisableLyrics {
 		return nil
 	}
 
-	for _, p := range lyrics.All() {
+	if len(t.SpotifyID) > 0 {
+		if result, ok := lyricsCache.Get(t.SpotifyID); ok && result == nil {
+			return fmt.Errorf("Lyrics not found (cached)")
+		} else if ok {
+			ui.Append("Using cached lyrics.", cui.DebugAppend)
+			t.Lyrics, t.SyncedLyrics = result.Text, result.Synced
+			return nil
+		}
+	}
+
+	// misses are only worth to be remembered if some provider
+	// answered, rather than failing, e.g. being unreachable
+	var answered bool
+	for _, p := range lyricsChain {
 		ui.Append(fmt.Sprintf("Searching lyrics using %s provider...", p.Name()), cui.DebugAppend)
 
-		text, err := p.Query(t.Song, t.Artist)
+		result, err := lyrics.Fetch(p, t.Song, t.Artist)
 		if err != nil {
+			var miss *lyrics.MissError
+			answered = answered || errors.As(err, &miss)
 			ui.Append(fmt.Sprintf("Provider encountered an error: %s", err.Error()))
 			continue
 		}
 
-		t.Lyrics = text
+		if len(t.SpotifyID) > 0 {
+			lyricsCache.Put(t.SpotifyID, result)
+		}
+		t.Lyrics, t.SyncedLyrics = result.Text, result.Synced
 		return nil
 	}
 
+	if len(t.SpotifyID) > 0 && answered {
+		lyricsCache.Put(t.SpotifyID, nil)
+	}
 	return fmt.Errorf("Lyrics not found")
 }
 
+func lyricsProviders() []lyrics.Provider {
+	var (
+		providers []lyrics.Provider
+		settings  = cfg.Lyrics.Providers
+	)
+
+	if len(settings) == 0 {
+		for _, name := range lyrics.DefaultProviders {
+			// unconfigured providers are silently left out
+			if p, err := lyrics.New(name, lyrics.Options{}); err == nil {
+				providers = append(providers, p)
+			}
+		}
+		return providers
+	}
+
+	for _, setting := range settings {
+		if !setting.IsEnabled() {
+			continue
+		}
+
+		p, err := lyrics.New(setting.Name, lyrics.Options{Token: setting.Token, URL: setting.URL})
+		if err != nil {
+			fmt.Println(fmt.Sprintf("Unable to set lyrics provider up: %s", err.Error()))
+			os.Exit(1)
+		}
+		providers = append(providers, p)
+	}
+
+	return providers
+}
+
 func songFetchArtwork(t *track.Track) error {
 	if len(t.ArtworkURL) == 0 {
 		return nil
@@ -748,6 +1661,7 @@ func songProcess(track *track.Track, opts *track.SyncOptions, wg *sync.WaitGroup
 	if !system.FileExists(track.FilenameTemporary()) {
 		if err := system.FileCopy(track.Filename(), track.FilenameTemporary()); err != nil {
 			ui.Append(err.Error(), cui.ErrorAppend)
+			trackFailed(track)
 			return
 		}
 	}
@@ -763,12 +1677,39 @@ func songProcess(track *track.Track, opts *track.SyncOptions, wg *sync.WaitGroup
 	}
 
 	// track rename
+	if len(track.Folder) > 0 {
+		system.Mkdir(track.Folder)
+	}
 	os.Remove(track.Filename())
 	if err := os.Rename(track.FilenameTemporary(), track.Filename()); err != nil {
 		ui.Append(fmt.Sprintf("Unable to move song to its final path: %s", err.Error()), cui.WarningAppend)
+		trackFailed(track)
+		return
+	}
+
+	// synced lyrics sidecar
+	if argLyricsSidecar && len(track.SyncedLyrics) > 0 {
+		if err := ioutil.WriteFile(track.FilenameLyrics(), []byte(track.SyncedLyrics.LRC()), 0644); err != nil {
+			ui.Append(fmt.Sprintf("Unable to write lyrics file: %s", err.Error()), cui.WarningAppend)
+		}
 	}
 }
 
+// songRename moves the song at given path to the filename of given
+// track, along with its synced lyrics sidecar, if any
+func songRename(t *track.Track, path string) error {
+	if err := os.Rename(path, t.Filename()); err != nil {
+		return err
+	}
+
+	if sidecar := track.LyricsFilename(path); system.FileExists(sidecar) {
+		if err := os.Rename(sidecar, t.FilenameLyrics()); err != nil {
+			ui.Append(fmt.Sprintf("Unable to rename lyrics file: %s", err.Error()), cui.WarningAppend)
+		}
+	}
+	return nil
+}
+
 func songNormalize(track *track.Track, opts *track.SyncOptions) error {
 	if !opts.Normalization {
 		return nil
@@ -791,21 +1732,254 @@ func songMetadataFlush(track *track.Track, opts *track.SyncOptions) error {
 		return nil
 	}
 
-	return track.Flush()
+	return track.Flush(opts)
 }
 
-func fetchDump(path string) (dump track.TracksDump, err error) {
+// fetchDumpSnapshot returns the tracks cached at given path as long as they have
+// been read from the source at given snapshot, regardless of their age: if no
+// snapshot is given, the cache cannot be told up to date and it is not used
+func fetchDumpSnapshot(path, snapshot string) (dump track.TracksDump, err error) {
 	if err := system.FetchGob(path, &dump); err != nil {
 		return dump, err
 	}
 
-	if time.Since(dump.Time) > cacheDuration {
-		return dump, fmt.Errorf("Tracks cache is obsolete")
+	if len(snapshot) == 0 {
+		return dump, fmt.Errorf("Tracks cache cannot be checked for changes")
+	}
+
+	if dump.Snapshot != snapshot {
+		return dump, fmt.Errorf("Tracks cache is outdated")
 	}
 
+	dumpRefresh(dump)
 	return dump, nil
 }
 
+// dumpRefresh updates the fields of given cached tracks which
+// are read from local songs, as these could have changed since
+func dumpRefresh(dump track.TracksDump) {
+	for _, t := range dump.Tracks {
+		t.ReadLocal()
+	}
+}
+
+// cacheStatus returns the human readable status of given cached tracks
+func cacheStatus(dump track.TracksDump) string {
+	return fmt.Sprintf("%s, unchanged", durafmt.ParseShort(time.Since(dump.Time)).String())
+}
+
+// fileSnapshot returns a cheap fingerprint of the file at given path,
+// which changes as soon as its content gets modified
+func fileSnapshot(path string) (string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	hash := fnv.New64a()
+	hash.Write(content)
+	return fmt.Sprintf("%d-%x", len(content), hash.Sum64()), nil
+}
+
+// searchPick lists given candidates and returns the one to be synchronized
+// for given query: the best one or, in interactive mode, the one user picks
+func searchPick(query string, candidates []*spotify.Track) *spotify.Track {
+	for _, candidate := range candidates {
+		ui.Append(fmt.Sprintf("Candidate: %s - %s (%s), similarity %d%%",
+			candidate.Artists[0].Name, candidate.Name, candidate.Album.Name,
+			spotify.Similarity(candidate, query)), cui.DebugAppend)
+	}
+
+	if !argInteractive {
+		if len(candidates) == 0 || spotify.Similarity(candidates[0], query) < spotify.SimilarityThreshold {
+			return nil
+		}
+		return candidates[0]
+	}
+
+	for _, candidate := range candidates {
+		if ui.Prompt(
+			fmt.Sprintf("Search: %s\n\nArtist: %s\nTitle: %s\nAlbum: %s\nSimilarity: %d%%\nPick this track?",
+				query, candidate.Artists[0].Name, candidate.Name, candidate.Album.Name,
+				spotify.Similarity(candidate, query)),
+			cui.PromptBinary) {
+			return candidate
+		}
+	}
+
+	return nil
+}
+
+// sourceFiltered returns true if the source with given name and owners
+// is not matching --include expressions or it is matching --exclude ones
+func sourceFiltered(name string, owners ...string) bool {
+	var (
+		fields   = append([]string{name}, owners...)
+		matching = func(exprs []string) bool {
+			for _, expr := range exprs {
+				for _, field := range fields {
+					if match, _ := regexp.MatchString("(?i)"+expr, field); match {
+						return true
+					}
+				}
+			}
+			return false
+		}
+	)
+
+	if argInclude.IsSet() && !matching(argInclude.Entries) {
+		return true
+	}
+
+	return matching(argExclude.Entries)
+}
+
+// consumable records given source tracks, if the source has been chosen
+// to be consumed, so that they can be removed from it once synchronized
+func consumable(source string, sourceTracks []*track.Track) {
+	for _, entry := range argConsume.Entries {
+		if alias := cfg.URI(entry); len(alias) > 0 {
+			entry = alias
+		}
+
+		if (source == consumeLibrary) != (entry == consumeLibrary) {
+			continue
+		}
+
+		if source == consumeLibrary || spotify.IDFromURI(entry) == spotify.IDFromURI(source) {
+			consumables[source] = sourceTracks
+			return
+		}
+	}
+}
+
+// consume removes from the consumed sources exactly the songs which
+// have been synchronized, leaving the failed ones in place
+func consume() {
+	if argSimulate || len(consumables) == 0 {
+		return
+	}
+
+	failed := make(map[string]bool)
+	for _, t := range tracksFailed {
+		failed[t.SpotifyID] = true
+	}
+
+	for source, sourceTracks := range consumables {
+		var (
+			ids      []spotify.ID
+			consumed []*track.Track
+			left     []*track.Track
+		)
+		for _, t := range sourceTracks {
+			if t.Local() && !failed[t.SpotifyID] {
+				ids = append(ids, spotify.ID(t.SpotifyID))
+				consumed = append(consumed, t)
+			} else {
+				left = append(left, t)
+			}
+		}
+
+		if len(ids) == 0 {
+			continue
+		}
+
+		var (
+			err error
+			gob = fmt.Sprintf(usrGob, cUserID, "library")
+		)
+		ui.Append(fmt.Sprintf("Removing %d synchronized song(s) from %s...", len(ids), source))
+		if source == consumeLibrary {
+			err = c.RemoveLibraryTracks(ids)
+		} else {
+			gob = fmt.Sprintf(usrGob, cUserID, fmt.Sprintf("playlist_%s", spotify.IDFromURI(source)))
+			err = c.RemovePlaylistTracks(source, ids)
+		}
+
+		if err != nil {
+			ui.Append(fmt.Sprintf("Unable to remove synchronized songs: %s", err.Error()), cui.WarningAppend)
+			continue
+		}
+
+		// cached source tracks are not accurate anymore, hence they get refetched,
+		// but they still have to link the songs left, while consumed songs
+		// are linked by a cache of their own, not to be pruned
+		if err := system.DumpGob(gob, track.TracksDump{Tracks: left, Time: time.Now()}); err != nil {
+			os.Remove(gob)
+		}
+
+		var (
+			consumedGob  = fmt.Sprintf(usrGob, cUserID, "consumed")
+			consumedDump track.TracksDump
+		)
+		system.FetchGob(consumedGob, &consumedDump)
+		consumedDump.Tracks, consumedDump.Time = append(consumedDump.Tracks, consumed...), time.Now()
+		if err := system.DumpGob(consumedGob, consumedDump); err != nil {
+			ui.Append(fmt.Sprintf("Unable to cache consumed songs: %s", err.Error()), cui.WarningAppend)
+		}
+	}
+}
+
+func trackFailed(t *track.Track) {
+	tracksMutex.Lock()
+	defer tracksMutex.Unlock()
+	tracksFailed = append(tracksFailed, t)
+}
+
+func contains(entries []string, value string) bool {
+	for _, entry := range entries {
+		if entry == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sessionUser() (string, error) {
+	if len(argUser) > 0 {
+		return argUser, nil
+	}
+
+	var user string
+	if err := system.FetchGob(usrSession, &user); err != nil {
+		return "", err
+	}
+
+	return user, nil
+}
+
+func fetchToken() (*spotify.Token, error) {
+	user, err := sessionUser()
+	if err != nil {
+		return nil, err
+	}
+
+	token := new(spotify.Token)
+	if err := system.FetchGob(fmt.Sprintf(usrGob, user, "token"), token); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func dumpToken() error {
+	token, err := c.Token()
+	if err != nil {
+		return err
+	}
+
+	var gob = fmt.Sprintf(usrGob, cUserID, "token")
+	if err := system.DumpGob(gob, token); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(gob, 0600); err != nil {
+		return err
+	}
+
+	return system.DumpGob(usrSession, cUserID)
+}
+
 func countSongs() (int, int, int) {
 	var (
 		fetch  int
@@ -829,13 +2003,13 @@ func countSongs() (int, int, int) {
 }
 
 func flushPlaylists() {
-	if argSimulate || argDisablePlaylistFile || len(argPlaylists.Entries) == 0 {
+	if argSimulate || argDisablePlaylistFile || len(playlists) == 0 {
 		return
 	}
 
 	for _, p := range playlists {
 		var (
-			pFname   = slug.Make(p.Name)
+			pFname   = filepath.Join(p.Folder, slug.Make(p.Name))
 			pContent string
 		)
 
@@ -845,6 +2019,10 @@ func flushPlaylists() {
 			pFname = pFname + ".m3u"
 		}
 
+		if len(p.Folder) > 0 {
+			system.Mkdir(p.Folder)
+		}
+
 		os.Remove(pFname)
 		ui.Append(fmt.Sprintf("Creating playlist file at %s...", pFname))
 		if system.FileExists(pFname) {
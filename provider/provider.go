@@ -3,20 +3,32 @@ package provider
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strings"
+	"sync"
 
-	"github.com/agnivade/levenshtein"
 	"github.com/gosimple/slug"
+	"github.com/streambinder/spotitube/shell"
+	"github.com/streambinder/spotitube/system"
 	"github.com/streambinder/spotitube/track"
 )
 
 const (
 	durationDeltaTolerance = 20 // second(s)
+	// ShortlistSize is the number of best candidates worth
+	// to be shown for a track
+	ShortlistSize = 5
+)
+
+var (
+	// Downloader is the command providers use to download entries
+	Downloader shell.Downloader = shell.YoutubeDL()
 )
 
 // All return the array of usable providers
 func All() []Provider {
 	return []Provider{
+		new(YouTubeMusicProvider),
 		new(YouTubeProvider),
 	}
 }
@@ -38,7 +50,11 @@ type Entry struct {
 	URL      string
 	Title    string
 	User     string
+	Album    string
 	Duration int
+	// Official is set for entries coming from official
+	// uploads, as the ones made by labels and distributors
+	Official bool
 }
 
 // Empty returns true if entry does not have a URL and it's unusable, then
@@ -57,6 +73,83 @@ func (e *Entry) Repr() string {
 	return ""
 }
 
+// Candidate represents an entry found by a provider, scored
+// against the track it has been searched for
+type Candidate struct {
+	*Entry
+	Provider Provider
+	Score    int
+	Match    error
+}
+
+// Search queries all the providers for entries related to track and returns
+// them ranked by a score shared by all the providers, best first, along with
+// the errors met by failing providers, keyed by provider name
+func Search(t *track.Track) ([]*Candidate, map[string]error) {
+	var (
+		providers = All()
+		results   = make([][]*Candidate, len(providers))
+		errs      = make(map[string]error)
+		mutex     sync.Mutex
+		wg        sync.WaitGroup
+	)
+
+	for i, p := range providers {
+		wg.Add(1)
+		go func(i int, p Provider) {
+			defer wg.Done()
+
+			entries, err := p.Query(t)
+			if err != nil {
+				mutex.Lock()
+				errs[p.Name()] = err
+				mutex.Unlock()
+				return
+			}
+
+			var scorer Scorable = Scorer{}
+			if s, ok := p.(Scorable); ok {
+				scorer = s
+			}
+
+			for _, e := range entries {
+				results[i] = append(results[i], &Candidate{
+					Entry:    e,
+					Provider: p,
+					Score:    scorer.Score(e, t),
+					Match:    p.Match(e, t),
+				})
+			}
+		}(i, p)
+	}
+	wg.Wait()
+
+	var all []*Candidate
+	for _, result := range results {
+		all = append(all, result...)
+	}
+
+	// ties are won by earlier providers
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Score > all[j].Score
+	})
+
+	// the same video can be found by more than one provider:
+	// only its best scoring candidate is kept
+	var (
+		candidates []*Candidate
+		seen       = make(map[string]bool)
+	)
+	for _, c := range all {
+		if seen[c.Entry.ID] {
+			continue
+		}
+		seen[c.Entry.ID] = true
+		candidates = append(candidates, c)
+	}
+	return candidates, errs
+}
+
 // Provider defines the generic interface on which every download provider
 // should be basing its logic
 type Provider interface {
@@ -77,9 +170,14 @@ type Scorer struct {
 	Scorable
 }
 
-// Score implements a basic scoring logic usable by any Provider
+// Score implements a basic scoring logic usable by any Provider,
+// ranging from 0 to 100 so that entries of different providers compare
 func (s Scorer) Score(e *Entry, t *track.Track) int {
-	var score = 0 - levenshtein.ComputeDistance(t.Query(), fmt.Sprintf("%s %s", e.User, e.Title))
+	var score = system.Similarity(t.Query(), fmt.Sprintf("%s %s", e.User, e.Title)) * 2 / 5
+
+	if e.Official {
+		score += 10
+	}
 
 	if math.Abs(float64(t.Duration-e.Duration)) <= float64(durationDeltaTolerance/2) {
 		score += 20
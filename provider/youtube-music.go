@@ -0,0 +1,195 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/bradfitz/slice"
+	"github.com/streambinder/spotitube/system"
+	"github.com/streambinder/spotitube/track"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	youTubeMusicPrefix        = "https://music.youtube.com"
+	youTubeMusicSearchURL     = youTubeMusicPrefix + "/youtubei/v1/search?prettyPrint=false"
+	youTubeMusicWatchPattern  = youTubeMusicPrefix + "/watch?v=%s"
+	youTubeMusicClientName    = "WEB_REMIX"
+	youTubeMusicClientVersion = "1.20240101.01.00"
+	youTubeMusicUserAgent     = "Mozilla/5.0 (X11; Linux x86_64; rv:120.0) Gecko/20100101 Firefox/120.0"
+	youTubeMusicSongsParams   = "EgWKAQIIAWoKEAkQBRAKEAMQBA%3D%3D"
+	youTubeMusicVideosParams  = "EgWKAQIQAWoKEAkQChAFEAMQBA%3D%3D"
+	youTubeMusicPageArtist    = "MUSIC_PAGE_TYPE_ARTIST"
+	youTubeMusicPageAlbum     = "MUSIC_PAGE_TYPE_ALBUM"
+)
+
+var (
+	regMusicURL      = regexp.MustCompile(`(?m)music\.youtube\.com\/watch\?(?:.*&)?v=([^"&?\/ ]{11})`)
+	regMusicDuration = regexp.MustCompile(`^(\d+:)?\d+:\d{2}$`)
+)
+
+// YouTubeMusicProvider is the provider implementation which uses as source
+// YouTube Music songs, from official uploads, and videos.
+type YouTubeMusicProvider struct {
+	Provider
+	Scorer
+}
+
+// Name returns a human readable name for the provider
+func (p YouTubeMusicProvider) Name() string {
+	return "YouTube Music"
+}
+
+// Query searches provider for entries related to track,
+// looking for songs first and for videos then
+func (p YouTubeMusicProvider) Query(track *track.Track) ([]*Entry, error) {
+	var (
+		entries = []*Entry{}
+		err     error
+	)
+	for _, params := range []string{youTubeMusicSongsParams, youTubeMusicVideosParams} {
+		document, searchErr := youTubeMusicSearch(track.Query(), params)
+		if searchErr != nil {
+			err = searchErr
+			continue
+		}
+
+		entries = append(entries, pullTracksFromMusicJSON(document, params == youTubeMusicSongsParams)...)
+	}
+
+	if len(entries) == 0 && err != nil {
+		return entries, err
+	} else if len(entries) == 0 {
+		return entries, fmt.Errorf("No results found")
+	}
+
+	slice.Sort(entries[:], func(i, j int) bool { return p.Score(entries[i], track) > p.Score(entries[j], track) })
+	return entries, nil
+}
+
+// Match returns nil error if YouTube Music entry is matching with track
+func (p YouTubeMusicProvider) Match(entry *Entry, track *track.Track) error {
+	if err := p.Support(entry.URL); err != nil {
+		return err
+	}
+
+	if int(math.Abs(float64(track.Duration-entry.Duration))) > durationDeltaTolerance {
+		return fmt.Errorf("The duration delta too high")
+	}
+
+	return track.Seems(fmt.Sprintf("%s %s", entry.User, entry.Title))
+}
+
+// Download handles the downloader call to download entry
+func (p YouTubeMusicProvider) Download(e *Entry, fname string) error {
+	return YouTubeProvider{}.Download(e, fname)
+}
+
+// Support returns nil error if input URL is a valid YouTube Music URL
+func (p YouTubeMusicProvider) Support(url string) error {
+	if regMusicURL.FindAllString(url, -1) == nil {
+		return fmt.Errorf(fmt.Sprintf("URL %s doesn't seem to be pointing to any YouTube Music song", url))
+	}
+
+	return nil
+}
+
+func youTubeMusicSearch(query, params string) (string, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"context": map[string]interface{}{
+			"client": map[string]string{
+				"clientName":    youTubeMusicClientName,
+				"clientVersion": youTubeMusicClientVersion,
+				"hl":            "en",
+			},
+		},
+		"query":  query,
+		"params": params,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, youTubeMusicSearchURL, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Origin", youTubeMusicPrefix)
+	req.Header.Add("Referer", youTubeMusicPrefix+"/")
+	req.Header.Add("User-Agent", youTubeMusicUserAgent)
+
+	res, err := system.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("YouTube Music responded with status %d", res.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return string(body), nil
+}
+
+// pullTracksFromMusicJSON parses the results of a YouTube Music search,
+// whose items show title in the first column and the artists, album
+// (songs only) and duration in the second one, separated by bullets
+func pullTracksFromMusicJSON(document string, songs bool) []*Entry {
+	var entries = []*Entry{}
+	gjson.Get(document, "contents.tabbedSearchResultsRenderer.tabs.0.tabRenderer.content.sectionListRenderer.contents").ForEach(func(_, section gjson.Result) bool {
+		section.Get("musicShelfRenderer.contents").ForEach(func(_, item gjson.Result) bool {
+			var (
+				renderer = item.Get("musicResponsiveListItemRenderer")
+				artists  []string
+				e        = &Entry{
+					ID:       renderer.Get("playlistItemData.videoId").String(),
+					Title:    renderer.Get("flexColumns.0.musicResponsiveListItemFlexColumnRenderer.text.runs.0.text").String(),
+					Official: songs,
+				}
+			)
+
+			if e.ID == "" {
+				e.ID = renderer.Get("overlay.musicItemThumbnailOverlayRenderer.content.musicPlayButtonRenderer.playNavigationEndpoint.watchEndpoint.videoId").String()
+			}
+			e.URL = fmt.Sprintf(youTubeMusicWatchPattern, e.ID)
+
+			for i, run := range renderer.Get("flexColumns.1.musicResponsiveListItemFlexColumnRenderer.text.runs").Array() {
+				text := strings.TrimSpace(run.Get("text").String())
+				switch run.Get("navigationEndpoint.browseEndpoint.browseEndpointContextSupportedConfigs.browseEndpointContextMusicConfig.pageType").String() {
+				case youTubeMusicPageArtist:
+					artists = append(artists, text)
+				case youTubeMusicPageAlbum:
+					e.Album = text
+				default:
+					if regMusicDuration.MatchString(text) {
+						e.Duration = system.ColonDuration(text)
+					} else if i == 0 {
+						// uploaders of videos are not always linked
+						artists = append(artists, text)
+					}
+				}
+			}
+			e.User = strings.Join(artists, ", ")
+
+			if e.ID != "" && e.Title != "" && e.User != "" && e.Duration > 0 {
+				entries = append(entries, e)
+			}
+			return true
+		})
+		return true
+	})
+
+	return entries
+}
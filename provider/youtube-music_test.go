@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestPullTracksFromMusicJSON(t *testing.T) {
+	for _, c := range []struct {
+		fixture string
+		songs   bool
+		entries []Entry
+	}{
+		{"testdata/youtube-music-songs.json", true, []Entry{
+			{ID: "lYBUbBu4W08", Title: "Bohemian Rhapsody", User: "Queen", Album: "A Night At The Opera", Duration: 355, Official: true},
+			{ID: "a01QQZyl-_I", Title: "Under Pressure", User: "Queen, David Bowie", Album: "Hot Space", Duration: 249, Official: true},
+		}},
+		{"testdata/youtube-music-videos.json", false, []Entry{
+			{ID: "fJ9rUzIMcZQ", Title: "Queen - Bohemian Rhapsody (Official Video Remastered)", User: "Queen Official", Duration: 360},
+			{ID: "xOJNmGu2PQg", Title: "Bohemian Rhapsody (Live Aid 1985)", User: "Classic Rock Archive", Duration: 3858},
+		}},
+	} {
+		document, err := ioutil.ReadFile(c.fixture)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		entries := pullTracksFromMusicJSON(string(document), c.songs)
+		if len(entries) != len(c.entries) {
+			t.Fatalf("%s: expected %d entries, got %d", c.fixture, len(c.entries), len(entries))
+		}
+
+		for i, e := range entries {
+			expected := c.entries[i]
+			if e.ID != expected.ID {
+				t.Errorf("%s: entry %d: expected ID %s, got %s", c.fixture, i, expected.ID, e.ID)
+			}
+			if e.URL != "https://music.youtube.com/watch?v="+expected.ID {
+				t.Errorf("%s: entry %d: unexpected URL %s", c.fixture, i, e.URL)
+			}
+			if e.Title != expected.Title {
+				t.Errorf("%s: entry %d: expected title %s, got %s", c.fixture, i, expected.Title, e.Title)
+			}
+			if e.User != expected.User {
+				t.Errorf("%s: entry %d: expected user %s, got %s", c.fixture, i, expected.User, e.User)
+			}
+			if e.Album != expected.Album {
+				t.Errorf("%s: entry %d: expected album %s, got %s", c.fixture, i, expected.Album, e.Album)
+			}
+			if e.Duration != expected.Duration {
+				t.Errorf("%s: entry %d: expected duration %d, got %d", c.fixture, i, expected.Duration, e.Duration)
+			}
+			if e.Official != expected.Official {
+				t.Errorf("%s: entry %d: expected official %t, got %t", c.fixture, i, expected.Official, e.Official)
+			}
+		}
+	}
+}
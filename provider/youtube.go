@@ -9,7 +9,6 @@ import (
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/bradfitz/slice"
-	"github.com/streambinder/spotitube/shell"
 	"github.com/streambinder/spotitube/system"
 	"github.com/streambinder/spotitube/track"
 	"github.com/tidwall/gjson"
@@ -82,7 +81,7 @@ func (p YouTubeProvider) Download(e *Entry, fname string) error {
 		base = fname[0 : len(fname)-(len(ext)+1)]
 	)
 
-	return shell.YoutubeDL().Download(e.URL, base, ext)
+	return Downloader.Download(e.URL, base, ext)
 }
 
 // Support returns nil error if input URL is a valid YouTube URL
@@ -113,11 +112,11 @@ func pullTracksFromDoc(track track.Track, document string) ([]*Entry, error) {
 	var json = strings.Split(strings.Split(document, youTubeResultsLinePrefix)[1], youTubeResultsLinePrefix)[0]
 	gjson.Get(json, "contents.twoColumnSearchResultsRenderer.primaryContents.sectionListRenderer.contents.0.itemSectionRenderer.contents").ForEach(func(key, value gjson.Result) bool {
 		e := &Entry{
-			gjson.Get(value.String(), "videoRenderer.videoId").String(),
-			"https://youtu.be/" + gjson.Get(value.String(), "videoRenderer.videoId").String(),
-			gjson.Get(value.String(), "videoRenderer.title.runs.0.text").String(),
-			gjson.Get(value.String(), "videoRenderer.ownerText.runs.0.text").String(),
-			system.ColonDuration(gjson.Get(value.String(), "videoRenderer.lengthText.simpleText").String()),
+			ID:       gjson.Get(value.String(), "videoRenderer.videoId").String(),
+			URL:      "https://youtu.be/" + gjson.Get(value.String(), "videoRenderer.videoId").String(),
+			Title:    gjson.Get(value.String(), "videoRenderer.title.runs.0.text").String(),
+			User:     gjson.Get(value.String(), "videoRenderer.ownerText.runs.0.text").String(),
+			Duration: system.ColonDuration(gjson.Get(value.String(), "videoRenderer.lengthText.simpleText").String()),
 		}
 		if e.ID != "" && e.Title != "" && e.User != "" && e.Duration > 0 {
 			entries = append(entries, e)
@@ -0,0 +1,56 @@
+package shell
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Downloader is an interface which functions as wrapper
+// for the applications able to download media assets
+type Downloader interface {
+	Command
+	Download(url, filename, extension string) error
+}
+
+// NewDownloader returns the downloader identified by given name, using
+// given binary path, if any, and appending given arguments to its calls
+// If no name is given, it is told by the binary name, if a path is given,
+// or the first installed downloader is returned, preferring yt-dlp.
+func NewDownloader(name, path string, args []string) (Downloader, error) {
+	if len(name) == 0 && len(path) > 0 {
+		// both downloaders share the version format,
+		// hence the binary name is all they can be told by
+		switch binaryName := strings.ToLower(filepath.Base(path)); {
+		case strings.Contains(binaryName, YtDlp().Name()):
+			name = YtDlp().Name()
+		case strings.Contains(binaryName, YoutubeDL().Name()):
+			name = YoutubeDL().Name()
+		default:
+			return nil, fmt.Errorf("Cannot tell which downloader %s is: its backend has to be set", path)
+		}
+	}
+
+	switch strings.ToLower(name) {
+	case "":
+		for _, d := range []Downloader{YtDlp(), YoutubeDL()} {
+			if d.Exists() {
+				return NewDownloader(d.Name(), "", args)
+			}
+		}
+		return nil, fmt.Errorf("Neither yt-dlp nor youtube-dl command is installed")
+	case YtDlp().Name():
+		return YtDlpCommand{Path: path, Args: args}, nil
+	case YoutubeDL().Name():
+		return YoutubeDLCommand{Path: path, Args: args}, nil
+	}
+
+	return nil, fmt.Errorf("Unknown downloader %s", name)
+}
+
+func binary(name, path string) string {
+	if len(path) > 0 {
+		return path
+	}
+	return name
+}
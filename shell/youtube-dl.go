@@ -11,6 +11,8 @@ import (
 // YoutubeDLCommand command wrapper implementation
 type YoutubeDLCommand struct {
 	Command
+	Path string
+	Args []string
 }
 
 // YoutubeDL returns a new YoutubeDLCommand instance
@@ -25,7 +27,7 @@ func (c YoutubeDLCommand) Name() string {
 
 // Exists returns true if the command is installed, false otherwise
 func (c YoutubeDLCommand) Exists() bool {
-	return system.Which(c.Name())
+	return system.Which(binary(c.Name(), c.Path))
 }
 
 // Version returns the command installed version
@@ -35,7 +37,7 @@ func (c YoutubeDLCommand) Version() (version string) {
 		cmdReg = regexp.MustCompile("\\d+\\.\\d+\\.\\d+")
 	)
 
-	cmd := exec.Command(c.Name(), []string{"--version"}...)
+	cmd := exec.Command(binary(c.Name(), c.Path), []string{"--version"}...)
 	cmd.Stdout = &cmdOut
 	if err := cmd.Run(); err != nil {
 		return
@@ -47,9 +49,10 @@ func (c YoutubeDLCommand) Version() (version string) {
 // Download attempts to download asset at given url
 // to given filename using given extension
 func (c YoutubeDLCommand) Download(url, filename, extension string) error {
-	return exec.Command("youtube-dl", []string{
+	var args = append([]string{
 		"--format", "bestaudio", "--extract-audio",
 		"--audio-format", extension,
 		"--audio-quality", "0",
-		"--output", filename + ".%(ext)s", url}...).Run()
+		"--output", filename + ".%(ext)s"}, c.Args...)
+	return exec.Command(binary(c.Name(), c.Path), append(args, url)...).Run()
 }
@@ -0,0 +1,64 @@
+package shell
+
+import (
+	"bytes"
+	"os/exec"
+	"regexp"
+
+	"github.com/streambinder/spotitube/system"
+)
+
+// YtDlpCommand command wrapper implementation
+type YtDlpCommand struct {
+	Command
+	Path string
+	Args []string
+}
+
+// YtDlp returns a new YtDlpCommand instance
+func YtDlp() YtDlpCommand {
+	return YtDlpCommand{}
+}
+
+// Name returns the effective name of the command
+func (c YtDlpCommand) Name() string {
+	return "yt-dlp"
+}
+
+// Exists returns true if the command is installed, false otherwise
+func (c YtDlpCommand) Exists() bool {
+	return system.Which(binary(c.Name(), c.Path))
+}
+
+// Version returns the command installed version
+func (c YtDlpCommand) Version() (version string) {
+	var (
+		cmdOut bytes.Buffer
+		cmdReg = regexp.MustCompile("\\d+\\.\\d+\\.\\d+")
+	)
+
+	cmd := exec.Command(binary(c.Name(), c.Path), []string{"--version"}...)
+	cmd.Stdout = &cmdOut
+	if err := cmd.Run(); err != nil {
+		return
+	}
+
+	return cmdReg.FindString(cmdOut.String())
+}
+
+// Download attempts to download asset at given url
+// to given filename using given extension
+func (c YtDlpCommand) Download(url, filename, extension string) error {
+	// metadata are flushed later on, hence the ones
+	// yt-dlp would embed are just getting in the way
+	var args = append([]string{
+		"--format", "bestaudio/best",
+		"--format-sort", "abr,asr",
+		"--no-playlist", "--no-embed-metadata",
+		"--no-embed-thumbnail", "--no-progress",
+		"--extract-audio",
+		"--audio-format", extension,
+		"--audio-quality", "0",
+		"--output", filename + ".%(ext)s"}, c.Args...)
+	return exec.Command(binary(c.Name(), c.Path), append(args, url)...).Run()
+}
@@ -1,25 +1,44 @@
 package spotify
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 
 	"github.com/zmb3/spotify"
+	"golang.org/x/oauth2"
 )
 
 // Playlist is an alias for Spotify FullPlaylist
 type Playlist = spotify.FullPlaylist
 
+// SimplePlaylist is an alias for Spotify SimplePlaylist
+type SimplePlaylist = spotify.SimplePlaylist
+
 // Album is an alias for Spotify FullAlbum
 type Album = spotify.FullAlbum
 
+// SimpleAlbum is an alias for Spotify SimpleAlbum
+type SimpleAlbum = spotify.SimpleAlbum
+
+// Artist is an alias for Spotify FullArtist
+type Artist = spotify.FullArtist
+
 // Track is an alias for Spotify FullTrack
 type Track = spotify.FullTrack
 
+// AudioFeatures is an alias for Spotify AudioFeatures
+type AudioFeatures = spotify.AudioFeatures
+
 // ID is an alias for Spotify ID
 type ID = spotify.ID
 
+// Token is an alias for OAuth2 Token
+type Token = oauth2.Token
+
 const (
+	apiURL             = "https://api.spotify.com/v1/"
 	clientID           = ""
 	clientIDEnvKey     = "SPOTIFY_ID"
 	clientSecret       = ""
@@ -29,13 +48,63 @@ const (
 // Ready returns an error if further configurations
 // are needed to access Spotify Apis
 func Ready() error {
-	if len(clientID) != 32 && len(os.Getenv(clientIDEnvKey)) != 32 {
+	if id, _ := credentials(); len(id) != 32 {
 		return fmt.Errorf(clientIDEnvKey + " not found")
 	}
 
-	if len(clientSecret) != 32 && len(os.Getenv(clientSecretEnvKey)) != 32 {
-		return fmt.Errorf(clientSecretEnvKey + " not found")
+	return nil
+}
+
+// PKCE returns true if authentication has to go through
+// the Authorization Code with PKCE flow, as no client secret is set
+func PKCE() bool {
+	_, secret := credentials()
+	return len(secret) != 32
+}
+
+func credentials() (id, secret string) {
+	id, secret = os.Getenv(clientIDEnvKey), os.Getenv(clientSecretEnvKey)
+	if len(id) == 0 {
+		id = clientID
+	}
+	if len(secret) == 0 {
+		secret = clientSecret
 	}
+	return
+}
 
-	return nil
+// get performs an authenticated request against given Spotify Web API endpoint,
+// decoding its response into result, retrying it as the other calls do
+func (c *Client) get(endpoint string, result interface{}) error {
+	var attempts int
+	for {
+		err := c.getOnce(endpoint, result)
+		if err == nil {
+			return nil
+		}
+
+		if kind, err := c.handleError(err, &attempts); kind == errorStrict {
+			return err
+		}
+	}
+}
+
+func (c *Client) getOnce(endpoint string, result interface{}) error {
+	res, err := c.http.Get(apiURL + endpoint)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		var body struct {
+			Error spotify.Error `json:"error"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&body); err != nil || len(body.Error.Message) == 0 {
+			return spotify.Error{Message: res.Status, Status: res.StatusCode}
+		}
+		return body.Error
+	}
+
+	return json.NewDecoder(res.Body).Decode(result)
 }
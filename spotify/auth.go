@@ -2,21 +2,30 @@ package spotify
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
-	"os"
+	"net/url"
 	"strings"
 
 	"github.com/streambinder/spotitube/shell"
 	"github.com/zmb3/spotify"
+	"golang.org/x/oauth2"
 )
 
 // Client wraps the Spotify API client object
 type Client struct {
 	*spotify.Client
-	AuthChan chan *spotify.Client
+	AuthChan chan *Token
+	Retries  int
+	source   oauth2.TokenSource
+	http     *http.Client
+	albums   map[ID]*Album
+	labels   map[ID]string
+	genres   map[ID][]string
 }
 
 // AuthURL represents a Spotify authentication URL
@@ -85,26 +94,8 @@ const (
 
 // BuildAuthURL generates new authentication URL
 func BuildAuthURL(callbackHost string) *AuthURL {
-	var (
-		spotifyID  = os.Getenv(clientIDEnvKey)
-		spotifyKey = os.Getenv(clientSecretEnvKey)
-	)
-	if len(spotifyID) == 0 {
-		spotifyID = clientID
-	}
-	if len(spotifyKey) == 0 {
-		spotifyKey = clientSecret
-	}
-	clientAuthenticator = spotify.NewAuthenticator(
-		fmt.Sprintf(redirectURL, callbackHost),
-		spotify.ScopeUserLibraryRead,
-		spotify.ScopeUserLibraryModify,
-		spotify.ScopePlaylistReadPrivate,
-		spotify.ScopePlaylistReadCollaborative,
-		spotify.ScopePlaylistModifyPublic,
-		spotify.ScopePlaylistModifyPrivate)
-	clientAuthenticator.SetAuthInfo(spotifyID, spotifyKey)
-	spotifyURL := clientAuthenticator.AuthURL(clientState)
+	clientConfig = authConfig(callbackHost)
+	spotifyURL := clientConfig.AuthCodeURL(clientState, authCodeOptions()...)
 	tinyURL := fmt.Sprintf("http://tinyurl.com/api-create.php?url=%s", spotifyURL)
 	tinyResponse, tinyErr := http.Get(tinyURL)
 	if tinyErr != nil {
@@ -121,7 +112,7 @@ func BuildAuthURL(callbackHost string) *AuthURL {
 
 // Auth starts local callback server and Spotify authentication process
 func Auth(url string, authHost string, xdgOpen bool) (*Client, error) {
-	c := &Client{AuthChan: make(chan *spotify.Client)}
+	c := &Client{AuthChan: make(chan *Token)}
 	var authBind string
 	if strings.Contains(authHost, "127.0.0.1") || strings.Contains(authHost, "localhost") {
 		authBind = authHost
@@ -143,7 +134,7 @@ func Auth(url string, authHost string, xdgOpen bool) (*Client, error) {
 		}
 	}
 
-	c.Client = <-c.AuthChan
+	c.authenticate(clientConfig, <-c.AuthChan)
 	if authServer != nil {
 		authServer.Shutdown(context.Background())
 	}
@@ -151,22 +142,133 @@ func Auth(url string, authHost string, xdgOpen bool) (*Client, error) {
 	return c, nil
 }
 
-func (c *Client) callbackHandler(w http.ResponseWriter, r *http.Request) {
-	tok, err := clientAuthenticator.Token(clientState, r)
+// AuthCode completes the authentication process exchanging the code
+// found in given input, which is either the full URL the browser
+// got redirected to or the bare code
+func AuthCode(input string) (*Client, error) {
+	code := strings.TrimSpace(input)
+	if u, err := url.Parse(code); err == nil && len(u.RawQuery) > 0 {
+		if msg := u.Query().Get("error"); len(msg) > 0 {
+			return nil, fmt.Errorf("Authorization denied: %s", msg)
+		}
+
+		if st := u.Query().Get("state"); st != clientState {
+			return nil, fmt.Errorf("Authorization state mismatch")
+		}
+
+		code = u.Query().Get("code")
+	}
+
+	if len(code) == 0 {
+		return nil, fmt.Errorf("No authorization code given")
+	}
+
+	tok, err := clientConfig.Exchange(context.Background(), code, exchangeOptions()...)
 	if err != nil {
-		http.Error(w, callbackMessage("Couldn't get token", "none"), http.StatusForbidden)
+		return nil, err
+	}
+
+	c := new(Client)
+	c.authenticate(clientConfig, tok)
+	return c, nil
+}
+
+// Restore rebuilds an authenticated client out of a previously
+// obtained token, refreshing it if expired
+func Restore(token *Token) (*Client, error) {
+	c := new(Client)
+	c.authenticate(authConfig("localhost"), token)
+	if _, err := c.CurrentUser(); err != nil {
+		return nil, err
 	}
 
+	return c, nil
+}
+
+// Token returns the token the client is currently authenticated with
+func (c *Client) Token() (*Token, error) {
+	if c.source == nil {
+		return nil, fmt.Errorf("Client is not authenticated")
+	}
+
+	return c.source.Token()
+}
+
+func (c *Client) authenticate(config *oauth2.Config, token *Token) {
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: &throttler{base: http.DefaultTransport}})
+	c.source = config.TokenSource(ctx, token)
+	c.http = oauth2.NewClient(ctx, c.source)
+	client := spotify.NewClient(c.http)
+	c.Client = &client
+}
+
+func (c *Client) callbackHandler(w http.ResponseWriter, r *http.Request) {
 	if st := r.FormValue("state"); st != clientState {
 		http.NotFound(w, r)
+		return
 	}
 
-	tokClient := clientAuthenticator.NewClient(tok)
-	c.AuthChan <- &tokClient
+	tok, err := clientConfig.Exchange(context.Background(), r.FormValue("code"), exchangeOptions()...)
+	if err != nil {
+		http.Error(w, callbackMessage("Couldn't get token", "none"), http.StatusForbidden)
+		return
+	}
+
+	c.AuthChan <- tok
 
 	fmt.Fprintf(w, callbackMessage("Login completed", "Come back to the shell and enjoy the magic!"))
 }
 
+func authConfig(callbackHost string) *oauth2.Config {
+	var authStyle = oauth2.AuthStyleAutoDetect
+	if PKCE() {
+		// public clients have to send their ID in the request body
+		authStyle = oauth2.AuthStyleInParams
+	}
+
+	spotifyID, spotifyKey := credentials()
+	return &oauth2.Config{
+		ClientID:     spotifyID,
+		ClientSecret: spotifyKey,
+		RedirectURL:  fmt.Sprintf(redirectURL, callbackHost),
+		Scopes: []string{
+			spotify.ScopeUserLibraryRead,
+			spotify.ScopeUserLibraryModify,
+			spotify.ScopePlaylistReadPrivate,
+			spotify.ScopePlaylistReadCollaborative,
+			spotify.ScopePlaylistModifyPublic,
+			spotify.ScopePlaylistModifyPrivate,
+		},
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   spotify.AuthURL,
+			TokenURL:  spotify.TokenURL,
+			AuthStyle: authStyle,
+		},
+	}
+}
+
+func authCodeOptions() []oauth2.AuthCodeOption {
+	if !PKCE() {
+		return nil
+	}
+
+	challenge := sha256.Sum256([]byte(clientVerifier))
+	return []oauth2.AuthCodeOption{
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+		oauth2.SetAuthURLParam("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:])),
+	}
+}
+
+func exchangeOptions() []oauth2.AuthCodeOption {
+	if !PKCE() {
+		return nil
+	}
+
+	return []oauth2.AuthCodeOption{
+		oauth2.SetAuthURLParam("code_verifier", clientVerifier),
+	}
+}
+
 func callbackMessage(title string, subtitle string) string {
 	return fmt.Sprintf(callbackTemplate, title, subtitle)
 }
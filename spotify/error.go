@@ -1,8 +1,15 @@
 package spotify
 
 import (
-	"strings"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
 	"time"
+
+	"github.com/zmb3/spotify"
 )
 
 type errorType int
@@ -11,18 +18,126 @@ const (
 	errorStrict = iota
 	errorRelaxed
 	_
-	throttleDelay = 5 * time.Second
+	// DefaultRetries is the number of attempts made by default
+	// before giving up on a failing request
+	DefaultRetries  = 5
+	retryDelayBase  = 1 * time.Second
+	retryDelayCap   = 1 * time.Minute
+	retryAfterLimit = 10 * time.Minute
 )
 
-func (c *Client) handleError(err error) errorType {
-	if strings.Contains(err.Error(), "rate limit") {
-		c.throttle()
-		return errorRelaxed
+// RetryError is returned when a request keeps failing
+// after all the allowed attempts
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+// Error returns the string representation of the error
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("Giving up after %d attempts: %s", e.Attempts, e.Err.Error())
+}
+
+// Unwrap returns the error which caused the last attempt to fail
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// throttleError is returned by the throttler for the responses
+// telling the request has to be retried, along with the delay
+// the Retry-After header asks to wait for, if any
+type throttleError struct {
+	Status     int
+	RetryAfter time.Duration
+}
+
+// Error returns the string representation of the error
+func (e *throttleError) Error() string {
+	return fmt.Sprintf("Spotify responded with status %d", e.Status)
+}
+
+// throttler is an http.RoundTripper turning the responses which
+// have to be retried into errors, so that each failing request
+// carries its own status and Retry-After header
+type throttler struct {
+	base http.RoundTripper
+}
+
+func (t *throttler) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.base.RoundTrip(req)
+	if err != nil || (res.StatusCode != http.StatusTooManyRequests && res.StatusCode < http.StatusInternalServerError) {
+		return res, err
+	}
+	defer res.Body.Close()
+
+	throttleErr := &throttleError{Status: res.StatusCode}
+	if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		throttleErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return nil, throttleErr
+}
+
+// handleError returns errorRelaxed, after having waited for the proper delay,
+// if the given error is transient and the request can be retried, or errorStrict
+// along with the error to give back otherwise
+func (c *Client) handleError(err error, attempts *int) (errorType, error) {
+	if !transient(err) {
+		return errorStrict, err
 	}
 
-	return errorStrict
+	var retryAfter time.Duration
+	var throttleErr *throttleError
+	if errors.As(err, &throttleErr) {
+		retryAfter = throttleErr.RetryAfter
+	}
+
+	*attempts++
+	if *attempts > c.retries() || retryAfter > retryAfterLimit {
+		return errorStrict, &RetryError{Attempts: *attempts, Err: err}
+	}
+
+	if retryAfter > 0 {
+		time.Sleep(retryAfter)
+	} else {
+		time.Sleep(backoff(*attempts))
+	}
+
+	return errorRelaxed, nil
+}
+
+func (c *Client) retries() int {
+	if c.Retries > 0 {
+		return c.Retries
+	}
+
+	return DefaultRetries
 }
 
-func (c *Client) throttle() {
-	time.Sleep(throttleDelay)
+func transient(err error) bool {
+	var (
+		status      int
+		apiErr      spotify.Error
+		throttleErr *throttleError
+	)
+	if errors.As(err, &throttleErr) {
+		status = throttleErr.Status
+	} else if errors.As(err, &apiErr) {
+		status = apiErr.Status
+	}
+
+	if status == http.StatusTooManyRequests || status >= http.StatusInternalServerError {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func backoff(attempt int) time.Duration {
+	delay := time.Duration(float64(retryDelayBase) * math.Pow(2, float64(attempt-1)))
+	if delay > retryDelayCap {
+		return retryDelayCap
+	}
+
+	return delay
 }
@@ -0,0 +1,40 @@
+package spotify
+
+const featuresChunk = 100
+
+// AudioFeatures returns a mapping between given track IDs and their audio
+// features, fetching them in batches
+func (c *Client) AudioFeatures(ids []ID) (map[ID]*AudioFeatures, error) {
+	var (
+		features = make(map[ID]*AudioFeatures)
+		attempts int
+	)
+	for lowerbound := 0; lowerbound < len(ids); {
+		upperbound := lowerbound + featuresChunk
+		if len(ids) < upperbound {
+			upperbound = len(ids)
+		}
+
+		chunk, err := c.GetAudioFeatures(ids[lowerbound:upperbound]...)
+		if err != nil {
+			switch kind, err := c.handleError(err, &attempts); kind {
+			case errorStrict:
+				return features, err
+			case errorRelaxed:
+				continue
+			}
+		}
+		attempts = 0
+
+		// tracks with no features are returned as null entries
+		for _, f := range chunk {
+			if f != nil {
+				features[f.ID] = f
+			}
+		}
+
+		lowerbound = upperbound
+	}
+
+	return features, nil
+}
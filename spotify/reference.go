@@ -0,0 +1,113 @@
+package spotify
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	// ResourceUnknown is the identifier for references whose kind cannot be told, as bare IDs
+	ResourceUnknown = iota
+	// ResourceTrack is the identifier for track references
+	ResourceTrack
+	// ResourceAlbum is the identifier for album references
+	ResourceAlbum
+	// ResourcePlaylist is the identifier for playlist references
+	ResourcePlaylist
+	// ResourceArtist is the identifier for artist references
+	ResourceArtist
+	// ResourceShow is the identifier for podcast show references
+	ResourceShow
+)
+
+var (
+	resourceKinds = map[string]int{
+		"track":    ResourceTrack,
+		"album":    ResourceAlbum,
+		"playlist": ResourcePlaylist,
+		"artist":   ResourceArtist,
+		"show":     ResourceShow,
+	}
+	resourceHosts = []string{"open.spotify.com", "play.spotify.com"}
+	regID         = regexp.MustCompile(`^[0-9A-Za-z]{22}$`)
+)
+
+// Resource represents a parsed reference to a Spotify resource
+type Resource struct {
+	Kind int
+	ID   ID
+}
+
+// KindName returns the human readable name of given resource kind
+func KindName(kind int) string {
+	for name, k := range resourceKinds {
+		if k == kind {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// ParseResource parses given reference, which can be a Spotify URI
+// (e.g. spotify:album:ID), an open.spotify.com link or a bare ID
+func ParseResource(reference string) (*Resource, error) {
+	reference = strings.TrimSpace(reference)
+
+	if regID.MatchString(reference) {
+		return &Resource{Kind: ResourceUnknown, ID: ID(reference)}, nil
+	}
+
+	if strings.HasPrefix(reference, "spotify:") {
+		return parseResourceParts(strings.Split(reference, ":")[1:], reference)
+	}
+
+	if !strings.Contains(reference, "://") {
+		reference = "https://" + reference
+	}
+
+	u, err := url.Parse(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, host := range resourceHosts {
+		if strings.EqualFold(u.Hostname(), host) {
+			return parseResourceParts(strings.Split(strings.Trim(u.Path, "/"), "/"), reference)
+		}
+	}
+
+	return nil, fmt.Errorf("%s is not a Spotify reference", reference)
+}
+
+// ParseResourceOf parses given reference as ParseResource does, returning
+// an error if the reference is pointing to a resource of a different kind
+func ParseResourceOf(reference string, kind int) (*Resource, error) {
+	resource, err := ParseResource(reference)
+	if err != nil {
+		return nil, err
+	}
+
+	if resource.Kind == ResourceUnknown {
+		resource.Kind = kind
+	} else if resource.Kind != kind {
+		return nil, fmt.Errorf("%s is pointing to a %s, not to a %s",
+			reference, KindName(resource.Kind), KindName(kind))
+	}
+
+	return resource, nil
+}
+
+// parseResourceParts walks given path parts, as spotify:user:USER:playlist:ID
+// or intl-it/album/ID, looking for the last kind and ID couple
+func parseResourceParts(parts []string, reference string) (*Resource, error) {
+	for i := len(parts) - 2; i >= 0; i-- {
+		kind, ok := resourceKinds[strings.ToLower(parts[i])]
+		if ok && regID.MatchString(parts[i+1]) {
+			return &Resource{Kind: kind, ID: ID(parts[i+1])}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s is not pointing to any known Spotify resource", reference)
+}
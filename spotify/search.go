@@ -0,0 +1,91 @@
+package spotify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bradfitz/slice"
+	"github.com/streambinder/spotitube/system"
+	"github.com/zmb3/spotify"
+)
+
+const (
+	searchLimit = 10
+	// SimilarityThreshold is the minimum similarity a search result
+	// needs to be considered as matching the query
+	SimilarityThreshold = 60
+)
+
+// SearchTracks searches for tracks matching given free text query,
+// as "Artist - Title", returning them ranked by similarity
+func (c *Client) SearchTracks(query string) ([]*Track, error) {
+	var (
+		tracks   []*Track
+		attempts int
+		limit    = searchLimit
+		options  = spotify.Options{Limit: &limit}
+		queries  = []string{query}
+	)
+
+	if artist, title, ok := splitQuery(query); ok {
+		queries = append([]string{fmt.Sprintf("artist:\"%s\" track:\"%s\"", artist, title)}, queries...)
+	}
+
+	for len(queries) > 0 && len(tracks) == 0 {
+		result, err := c.SearchOpt(queries[0], spotify.SearchTypeTrack, &options)
+		if err != nil {
+			switch kind, err := c.handleError(err, &attempts); kind {
+			case errorStrict:
+				return nil, err
+			case errorRelaxed:
+				continue
+			}
+		}
+		attempts = 0
+		queries = queries[1:]
+
+		if result.Tracks == nil {
+			continue
+		}
+
+		for i := range result.Tracks.Tracks {
+			tracks = append(tracks, &result.Tracks.Tracks[i])
+		}
+	}
+
+	slice.Sort(tracks[:], func(i, j int) bool {
+		return Similarity(tracks[i], query) > Similarity(tracks[j], query)
+	})
+	return tracks, nil
+}
+
+// Similarity returns a score, from 0 to 100, telling how much
+// given track is resembling given free text query
+func Similarity(t *Track, query string) int {
+	var artists []string
+	for _, artist := range t.Artists {
+		artists = append(artists, artist.Name)
+	}
+
+	artist, title, ok := splitQuery(query)
+	if !ok {
+		return system.Similarity(strings.Join(artists, " ")+" "+t.Name, query)
+	}
+
+	var artistScore int
+	for _, a := range artists {
+		if score := system.Similarity(a, artist); score > artistScore {
+			artistScore = score
+		}
+	}
+	return (artistScore + system.Similarity(t.Name, title)) / 2
+}
+
+func splitQuery(query string) (string, string, bool) {
+	parts := strings.SplitN(query, " - ", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), true
+}
@@ -0,0 +1,139 @@
+package spotify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/streambinder/spotitube/track"
+	"github.com/zmb3/spotify"
+)
+
+const (
+	episodesChunk = 50
+	episodeGenre  = "Podcast"
+)
+
+// Show represents a Spotify podcast show
+type Show struct {
+	ID            ID                `json:"id"`
+	Name          string            `json:"name"`
+	Publisher     string            `json:"publisher"`
+	Description   string            `json:"description"`
+	TotalEpisodes int               `json:"total_episodes"`
+	Images        []spotify.Image   `json:"images"`
+	ExternalURLs  map[string]string `json:"external_urls"`
+}
+
+// Episode represents a Spotify podcast episode
+type Episode struct {
+	ID                   ID                `json:"id"`
+	Name                 string            `json:"name"`
+	Description          string            `json:"description"`
+	Duration             int               `json:"duration_ms"`
+	ReleaseDate          string            `json:"release_date"`
+	ReleaseDatePrecision string            `json:"release_date_precision"`
+	Images               []spotify.Image   `json:"images"`
+	ExternalURLs         map[string]string `json:"external_urls"`
+}
+
+type episodePage struct {
+	Items []*Episode `json:"items"`
+	Next  string     `json:"next"`
+}
+
+// Show returns show for given URI
+func (c *Client) Show(uri string) (*Show, error) {
+	var show Show
+	if err := c.get(fmt.Sprintf("shows/%s?market=from_token", IDFromURI(uri)), &show); err != nil {
+		return nil, err
+	}
+
+	return &show, nil
+}
+
+// ShowEpisodes returns the newest episodes of given show,
+// at most limit of them (or all, if limit is not positive)
+// and none of them published before since (if not zero)
+func (c *Client) ShowEpisodes(show *Show, limit int, since time.Time) ([]*track.Track, error) {
+	var (
+		episodes []*track.Track
+		offset   int
+	)
+	for {
+		var page episodePage
+		if err := c.get(fmt.Sprintf("shows/%s/episodes?market=from_token&limit=%d&offset=%d",
+			show.ID, episodesChunk, offset), &page); err != nil {
+			return nil, err
+		}
+
+		// episodes are returned from the newest to the oldest
+		for _, episode := range page.Items {
+			// unavailable episodes are returned as null entries
+			if episode == nil {
+				continue
+			}
+
+			if limit > 0 && len(episodes) >= limit {
+				return episodes, nil
+			}
+
+			if date, err := episode.releaseTime(); !since.IsZero() && err == nil && date.Before(since) {
+				return episodes, nil
+			}
+
+			episodes = append(episodes, episode.track(show))
+		}
+
+		if len(page.Next) == 0 || len(page.Items) == 0 {
+			break
+		}
+		offset += len(page.Items)
+	}
+
+	return episodes, nil
+}
+
+func (e *Episode) releaseTime() (time.Time, error) {
+	switch e.ReleaseDatePrecision {
+	case "year":
+		return time.Parse("2006", e.ReleaseDate)
+	case "month":
+		return time.Parse("2006-01", e.ReleaseDate)
+	default:
+		return time.Parse("2006-01-02", e.ReleaseDate)
+	}
+}
+
+// track returns the track representation of the episode, which is then
+// looked up as any other track through the providers: Spotify does not
+// expose the audio of its own episodes nor the feed of RSS-hosted ones
+func (e *Episode) track(show *Show) *track.Track {
+	artwork := e.Images
+	if len(artwork) == 0 {
+		artwork = show.Images
+	}
+
+	var artworkURL string
+	if len(artwork) > 0 {
+		artworkURL = artwork[0].URL
+	}
+
+	return &track.Track{
+		Title:                strings.TrimSpace(e.Name),
+		Song:                 strings.TrimSpace(e.Name),
+		Artist:               show.Name,
+		Album:                show.Name,
+		Genres:               []string{episodeGenre},
+		Year:                 strings.Split(e.ReleaseDate, "-")[0],
+		ReleaseDate:          e.ReleaseDate,
+		ReleaseDatePrecision: e.ReleaseDatePrecision,
+		Description:          strings.TrimSpace(e.Description),
+		ArtworkURL:           artworkURL,
+		Duration:             e.Duration / 1000,
+		SpotifyID:            string(e.ID),
+		Folder:               track.Sanitize(show.Name),
+		AlbumArtist:          show.Publisher,
+		Label:                show.Publisher,
+	}
+}
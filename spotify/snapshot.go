@@ -0,0 +1,76 @@
+package spotify
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/zmb3/spotify"
+)
+
+// LibrarySnapshot returns a cheap fingerprint of user library,
+// which changes as soon as any track gets saved or removed
+func (c *Client) LibrarySnapshot() (string, error) {
+	var (
+		limit    = 1
+		options  = spotify.Options{Limit: &limit}
+		attempts int
+	)
+	for {
+		chunk, err := c.CurrentUsersTracksOpt(&options)
+		if err == nil {
+			if len(chunk.Tracks) == 0 {
+				return "empty", nil
+			}
+			return fmt.Sprintf("%d-%s-%s", chunk.Total, chunk.Tracks[0].ID, chunk.Tracks[0].AddedAt), nil
+		}
+
+		if kind, err := c.handleError(err, &attempts); kind == errorStrict {
+			return "", err
+		}
+	}
+}
+
+// AlbumSnapshot returns a cheap fingerprint of the album from given URI,
+// which changes as soon as its tracks get modified
+func (c *Client) AlbumSnapshot(uri string) (string, error) {
+	album, err := c.Album(IDFromURI(uri))
+	if err != nil {
+		return "", err
+	}
+
+	hash := fnv.New64a()
+	for _, t := range album.Tracks.Tracks {
+		hash.Write([]byte(t.ID))
+	}
+	return fmt.Sprintf("%d-%x", album.Tracks.Total, hash.Sum64()), nil
+}
+
+// ArtistSnapshot returns a cheap fingerprint of the discography of the artist
+// from given URI, limited to given album groups, which changes as soon as
+// any album gets released or removed
+func (c *Client) ArtistSnapshot(uri string, groups ...string) (string, error) {
+	albums, err := c.ArtistAlbums(uri, groups...)
+	if err != nil {
+		return "", err
+	}
+
+	hash := fnv.New64a()
+	for _, album := range albums {
+		hash.Write([]byte(album.ID))
+	}
+	return fmt.Sprintf("%d-%x", len(albums), hash.Sum64()), nil
+}
+
+// ShowSnapshot returns a cheap fingerprint of given show,
+// which changes as soon as any episode gets published or removed
+func (c *Client) ShowSnapshot(show *Show) (string, error) {
+	var page episodePage
+	if err := c.get(fmt.Sprintf("shows/%s/episodes?market=from_token&limit=1", show.ID), &page); err != nil {
+		return "", err
+	}
+
+	if len(page.Items) == 0 || page.Items[0] == nil {
+		return fmt.Sprintf("%d", show.TotalEpisodes), nil
+	}
+	return fmt.Sprintf("%d-%s", show.TotalEpisodes, page.Items[0].ID), nil
+}
@@ -1,16 +1,26 @@
 package spotify
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/streambinder/spotitube/track"
 	"github.com/thanhpk/randstr"
 	"github.com/zmb3/spotify"
+	"golang.org/x/oauth2"
+)
+
+const (
+	albumsChunk   = 20
+	tracksChunk   = 50
+	artistsChunk  = 50
+	playlistChunk = 100
 )
 
 var (
-	clientState         = randstr.Hex(20)
-	clientAuthenticator spotify.Authenticator
+	clientState    = randstr.Hex(20)
+	clientVerifier = randstr.Hex(32)
+	clientConfig   *oauth2.Config
 )
 
 // User returns session authenticated user
@@ -25,34 +35,26 @@ func (c *Client) User() (string, string) {
 // LibraryTracks returns library tracks
 func (c *Client) LibraryTracks() ([]*track.Track, error) {
 	var (
-		tracks     []*track.Track
+		tracks     []*Track
 		iterations int
+		attempts   int
 		options    = defaultOptions()
 	)
 	for true {
 		*options.Offset = *options.Limit * iterations
 		chunk, err := c.CurrentUsersTracksOpt(&options)
 		if err != nil {
-			switch c.handleError(err) {
+			switch kind, err := c.handleError(err, &attempts); kind {
 			case errorStrict:
 				return nil, err
 			case errorRelaxed:
 				continue
 			}
 		}
+		attempts = 0
 
-		for _, t := range chunk.Tracks {
-			tAlbum, err := c.Album(t.FullTrack.Album.ID)
-			if err != nil {
-				switch c.handleError(err) {
-				case errorStrict:
-					tAlbum = &Album{}
-				case errorRelaxed:
-					continue
-				}
-			}
-
-			tracks = append(tracks, track.ParseSpotifyTrack(&t.FullTrack, tAlbum))
+		for i := range chunk.Tracks {
+			tracks = append(tracks, &chunk.Tracks[i].FullTrack)
 		}
 
 		if len(chunk.Tracks) < 50 {
@@ -62,7 +64,7 @@ func (c *Client) LibraryTracks() ([]*track.Track, error) {
 		iterations++
 	}
 
-	return tracks, nil
+	return c.ParseTracks(tracks), nil
 }
 
 // RemoveLibraryTracks removes an array of tracks by their IDs from library
@@ -71,7 +73,10 @@ func (c *Client) RemoveLibraryTracks(ids []ID) error {
 		return nil
 	}
 
-	var iterations int
+	var (
+		iterations int
+		attempts   int
+	)
 	for true {
 		lowerbound := iterations * 50
 		upperbound := lowerbound + 50
@@ -81,15 +86,16 @@ func (c *Client) RemoveLibraryTracks(ids []ID) error {
 
 		chunk := ids[lowerbound:upperbound]
 		if err := c.RemoveTracksFromLibrary(chunk...); err != nil {
-			switch c.handleError(err) {
+			switch kind, err := c.handleError(err, &attempts); kind {
 			case errorStrict:
 				return err
 			case errorRelaxed:
 				continue
 			}
 		}
+		attempts = 0
 
-		if len(chunk) < 50 {
+		if upperbound >= len(ids) {
 			break
 		}
 
@@ -98,26 +104,95 @@ func (c *Client) RemoveLibraryTracks(ids []ID) error {
 	return nil
 }
 
+// SavedAlbums returns the albums saved into user library
+func (c *Client) SavedAlbums() ([]*Album, error) {
+	var (
+		albums     []*Album
+		iterations int
+		attempts   int
+		options    = defaultOptions()
+	)
+	for true {
+		*options.Offset = *options.Limit * iterations
+		chunk, err := c.CurrentUsersAlbumsOpt(&options)
+		if err != nil {
+			switch kind, err := c.handleError(err, &attempts); kind {
+			case errorStrict:
+				return nil, err
+			case errorRelaxed:
+				continue
+			}
+		}
+		attempts = 0
+
+		for i := range chunk.Albums {
+			albums = append(albums, &chunk.Albums[i].FullAlbum)
+		}
+
+		if len(chunk.Albums) < 50 {
+			break
+		}
+
+		iterations++
+	}
+
+	return albums, nil
+}
+
+// FollowedPlaylists returns the playlists owned or followed by the user
+func (c *Client) FollowedPlaylists() ([]SimplePlaylist, error) {
+	var (
+		playlists  []SimplePlaylist
+		iterations int
+		attempts   int
+		options    = defaultOptions()
+	)
+	for true {
+		*options.Offset = *options.Limit * iterations
+		chunk, err := c.CurrentUsersPlaylistsOpt(&options)
+		if err != nil {
+			switch kind, err := c.handleError(err, &attempts); kind {
+			case errorStrict:
+				return nil, err
+			case errorRelaxed:
+				continue
+			}
+		}
+		attempts = 0
+
+		playlists = append(playlists, chunk.Playlists...)
+
+		if len(chunk.Playlists) < 50 {
+			break
+		}
+
+		iterations++
+	}
+
+	return playlists, nil
+}
+
 // Playlist returns a Playlist object from given URI
 func (c *Client) Playlist(uri string) (*Playlist, error) {
-	playlist, err := c.GetPlaylist(IDFromURI(uri))
-	if err != nil {
-		switch c.handleError(err) {
-		case errorStrict:
+	var attempts int
+	for {
+		playlist, err := c.GetPlaylist(IDFromURI(uri))
+		if err == nil {
+			return playlist, nil
+		}
+
+		if kind, err := c.handleError(err, &attempts); kind == errorStrict {
 			return nil, err
-		case errorRelaxed:
-			return c.Playlist(uri)
 		}
 	}
-
-	return playlist, nil
 }
 
 // PlaylistTracks returns playlist tracks from given URI
 func (c *Client) PlaylistTracks(uri string) ([]*track.Track, error) {
 	var (
-		tracks     []*track.Track
+		tracks     []*Track
 		iterations int
+		attempts   int
 		options    = defaultOptions()
 	)
 
@@ -125,25 +200,21 @@ func (c *Client) PlaylistTracks(uri string) ([]*track.Track, error) {
 		*options.Offset = *options.Limit * iterations
 		chunk, err := c.GetPlaylistTracksOpt(IDFromURI(uri), &options, "")
 		if err != nil {
-			switch c.handleError(err) {
+			switch kind, err := c.handleError(err, &attempts); kind {
 			case errorStrict:
 				return nil, err
 			case errorRelaxed:
 				continue
 			}
 		}
+		attempts = 0
 
-		for _, t := range chunk.Tracks {
-			if t.IsLocal {
+		for i := range chunk.Tracks {
+			if chunk.Tracks[i].IsLocal {
 				continue
 			}
 
-			tAlbum, err := c.Album(t.Track.Album.ID)
-			if err != nil {
-				tAlbum = &Album{}
-			}
-
-			tracks = append(tracks, track.ParseSpotifyTrack(&t.Track, tAlbum))
+			tracks = append(tracks, &chunk.Tracks[i].Track)
 		}
 
 		if len(chunk.Tracks) < 50 {
@@ -152,7 +223,7 @@ func (c *Client) PlaylistTracks(uri string) ([]*track.Track, error) {
 
 		iterations++
 	}
-	return tracks, nil
+	return c.ParseTracks(tracks), nil
 }
 
 // RemovePlaylistTracks removes an array of tracks by their IDs from playlist
@@ -163,6 +234,7 @@ func (c *Client) RemovePlaylistTracks(uri string, ids []ID) error {
 
 	var (
 		iterations int
+		attempts   int
 	)
 	for true {
 		lowerbound := iterations * 50
@@ -174,15 +246,16 @@ func (c *Client) RemovePlaylistTracks(uri string, ids []ID) error {
 		chunk := ids[lowerbound:upperbound]
 		_, err := c.RemoveTracksFromPlaylist(IDFromURI(uri), chunk...)
 		if err != nil {
-			switch c.handleError(err) {
+			switch kind, err := c.handleError(err, &attempts); kind {
 			case errorStrict:
 				return err
 			case errorRelaxed:
 				continue
 			}
 		}
+		attempts = 0
 
-		if len(chunk) < 50 {
+		if upperbound >= len(ids) {
 			break
 		}
 
@@ -191,55 +264,405 @@ func (c *Client) RemovePlaylistTracks(uri string, ids []ID) error {
 	return nil
 }
 
+// PushPlaylist makes the playlist with given name, owned by given user, contain
+// exactly given tracks, in the same order, creating it if not existing yet
+func (c *Client) PushPlaylist(userID, name string, ids []ID) (ID, bool, error) {
+	var (
+		playlistID ID
+		created    bool
+		attempts   int
+	)
+
+	// an empty push would wipe the whole playlist content
+	if len(ids) == 0 {
+		return "", false, fmt.Errorf("No track to push to playlist %s", name)
+	}
+
+	followed, err := c.FollowedPlaylists()
+	if err != nil {
+		return "", false, err
+	}
+
+	for _, p := range followed {
+		if p.Owner.ID == userID && p.Name == name {
+			playlistID = p.ID
+			break
+		}
+	}
+
+	// creation is not retried, as a lost response would lead to duplicates
+	if len(playlistID) == 0 {
+		playlist, err := c.CreatePlaylistForUser(userID, name, "", false)
+		if err != nil {
+			return "", false, err
+		}
+		playlistID, created = playlist.ID, true
+	}
+
+	// first chunk replaces the whole playlist content, following ones are appended
+	for iterations := 0; iterations*playlistChunk < len(ids); {
+		lowerbound := iterations * playlistChunk
+		upperbound := lowerbound + playlistChunk
+		if len(ids) < upperbound {
+			upperbound = len(ids)
+		}
+
+		chunk := ids[lowerbound:upperbound]
+		if iterations == 0 {
+			err = c.ReplacePlaylistTracks(playlistID, chunk...)
+		} else if _, err = c.AddTracksToPlaylist(playlistID, chunk...); err != nil {
+			// appending is not idempotent and the request could have gone through
+			// even if its response got lost: playlist length tells whether to retry
+			length, lengthErr := c.playlistLength(playlistID)
+			if lengthErr != nil {
+				return playlistID, created, err
+			} else if length >= upperbound {
+				err = nil
+			}
+		}
+		if err != nil {
+			switch kind, err := c.handleError(err, &attempts); kind {
+			case errorStrict:
+				return playlistID, created, err
+			case errorRelaxed:
+				continue
+			}
+		}
+		attempts = 0
+
+		iterations++
+	}
+
+	return playlistID, created, nil
+}
+
+func (c *Client) playlistLength(id ID) (int, error) {
+	var limit = 1
+	page, err := c.GetPlaylistTracksOpt(id, &spotify.Options{Limit: &limit}, "total")
+	if err != nil {
+		return 0, err
+	}
+
+	return page.Total, nil
+}
+
+// labeledAlbum wraps the album object as returned by the API,
+// along with its record label, which the library does not expose
+type labeledAlbum struct {
+	Album
+	Label string `json:"label"`
+}
+
 // Album returns a Album object from given URI
 func (c *Client) Album(id ID) (*Album, error) {
-	album, err := c.GetAlbum(id)
-	if err != nil {
-		switch c.handleError(err) {
-		case errorStrict:
-			return nil, err
-		case errorRelaxed:
-			return c.Album(id)
+	if album, ok := c.albums[id]; ok {
+		return album, nil
+	}
+
+	var album labeledAlbum
+	if err := c.get(fmt.Sprintf("albums/%s", id), &album); err != nil {
+		return nil, err
+	}
+
+	c.memoAlbum(&album.Album, album.Label)
+	return &album.Album, nil
+}
+
+// Albums returns a mapping between given IDs and their Album objects,
+// fetching in batches only the ones not already met during this run
+func (c *Client) Albums(ids []ID) (map[ID]*Album, error) {
+	var (
+		albums  = make(map[ID]*Album)
+		missing []ID
+		seen    = make(map[ID]bool)
+	)
+	for _, id := range ids {
+		if len(id) == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if album, ok := c.albums[id]; ok && album != nil {
+			albums[id] = album
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	for lowerbound := 0; lowerbound < len(missing); {
+		upperbound := lowerbound + albumsChunk
+		if len(missing) < upperbound {
+			upperbound = len(missing)
+		}
+
+		var (
+			chunk struct {
+				Albums []*labeledAlbum `json:"albums"`
+			}
+			ids []string
+		)
+		for _, id := range missing[lowerbound:upperbound] {
+			ids = append(ids, string(id))
+		}
+
+		if err := c.get(fmt.Sprintf("albums?ids=%s", strings.Join(ids, ",")), &chunk); err != nil {
+			return albums, err
+		}
+
+		for _, album := range chunk.Albums {
+			if album != nil {
+				c.memoAlbum(&album.Album, album.Label)
+				albums[album.ID] = &album.Album
+			}
+		}
+
+		lowerbound = upperbound
+	}
+
+	return albums, nil
+}
+
+// ArtistsGenres returns a mapping between given artist IDs and their genres,
+// fetching in batches only the ones not already met during this run
+func (c *Client) ArtistsGenres(ids []ID) (map[ID][]string, error) {
+	if c.genres == nil {
+		c.genres = make(map[ID][]string)
+	}
+
+	var (
+		genres   = make(map[ID][]string)
+		missing  []ID
+		seen     = make(map[ID]bool)
+		attempts int
+	)
+	for _, id := range ids {
+		if len(id) == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if artistGenres, ok := c.genres[id]; ok {
+			genres[id] = artistGenres
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	for lowerbound := 0; lowerbound < len(missing); {
+		upperbound := lowerbound + artistsChunk
+		if len(missing) < upperbound {
+			upperbound = len(missing)
+		}
+
+		chunk, err := c.GetArtists(missing[lowerbound:upperbound]...)
+		if err != nil {
+			switch kind, err := c.handleError(err, &attempts); kind {
+			case errorStrict:
+				return genres, err
+			case errorRelaxed:
+				continue
+			}
 		}
+		attempts = 0
+
+		for _, artist := range chunk {
+			if artist != nil {
+				c.genres[artist.ID] = artist.Genres
+				genres[artist.ID] = artist.Genres
+			}
+		}
+
+		lowerbound = upperbound
 	}
 
-	return album, nil
+	return genres, nil
+}
+
+// Tracks returns the Track objects for given IDs, fetching them in batches
+func (c *Client) Tracks(ids []ID) ([]*Track, error) {
+	var (
+		tracks   []*Track
+		attempts int
+	)
+
+	for lowerbound := 0; lowerbound < len(ids); {
+		upperbound := lowerbound + tracksChunk
+		if len(ids) < upperbound {
+			upperbound = len(ids)
+		}
+
+		chunk, err := c.GetTracks(ids[lowerbound:upperbound]...)
+		if err != nil {
+			switch kind, err := c.handleError(err, &attempts); kind {
+			case errorStrict:
+				return tracks, err
+			case errorRelaxed:
+				continue
+			}
+		}
+		attempts = 0
+
+		for _, t := range chunk {
+			if t != nil {
+				tracks = append(tracks, t)
+			}
+		}
+
+		lowerbound = upperbound
+	}
+
+	return tracks, nil
+}
+
+// TracksFromURIs returns the tracks pointed by given URIs
+func (c *Client) TracksFromURIs(uris ...string) ([]*track.Track, error) {
+	var ids []ID
+	for _, uri := range uris {
+		ids = append(ids, IDFromURI(uri))
+	}
+
+	tracks, err := c.Tracks(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ParseTracks(tracks), nil
 }
 
 // AlbumTracks returns album tracks from given URI
 func (c *Client) AlbumTracks(uri string) ([]*track.Track, error) {
+	tracks, err := c.albumTracks(IDFromURI(uri))
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ParseTracks(tracks), nil
+}
+
+// Artist returns an Artist object from given URI
+func (c *Client) Artist(uri string) (*Artist, error) {
+	var attempts int
+	for {
+		artist, err := c.GetArtist(IDFromURI(uri))
+		if err == nil {
+			return artist, nil
+		}
+
+		if kind, err := c.handleError(err, &attempts); kind == errorStrict {
+			return nil, err
+		}
+	}
+}
+
+// ArtistAlbums returns the albums of the artist from given URI, restricted to
+// given groups (album, single, compilation, appears_on), skipping the releases
+// which are the same across different markets
+func (c *Client) ArtistAlbums(uri string, groups ...string) ([]SimpleAlbum, error) {
+	types, err := albumTypes(groups)
+	if err != nil {
+		return nil, err
+	}
+
 	var (
-		tracks     []*track.Track
+		albums     []SimpleAlbum
+		releases   = make(map[string]bool)
 		iterations int
+		attempts   int
+		market     = "from_token"
 		options    = defaultOptions()
 	)
+	options.Country = &market
 
 	for true {
 		*options.Offset = *options.Limit * iterations
-		chunk, err := c.GetAlbumTracksOpt(IDFromURI(uri), &options)
+		chunk, err := c.GetArtistAlbumsOpt(IDFromURI(uri), &options, types...)
 		if err != nil {
-			switch c.handleError(err) {
+			switch kind, err := c.handleError(err, &attempts); kind {
 			case errorStrict:
 				return nil, err
 			case errorRelaxed:
 				continue
 			}
 		}
+		attempts = 0
 
-		for _, t := range chunk.Tracks {
-			t, err := c.GetTrack(t.ID)
-			if err != nil {
+		for _, album := range chunk.Albums {
+			release := strings.ToLower(strings.Join([]string{album.AlbumGroup, album.Name, album.ReleaseDate}, "|"))
+			if releases[release] {
 				continue
 			}
 
-			tAlbum, err := c.Album(t.Album.ID)
-			if err != nil {
-				tAlbum = &Album{}
+			releases[release] = true
+			albums = append(albums, album)
+		}
+
+		if len(chunk.Albums) < 50 {
+			break
+		}
+
+		iterations++
+	}
+	return albums, nil
+}
+
+// ArtistTracks returns the tracks of all the albums of the artist from given URI,
+// restricted to given groups: from compilations and appears-on releases, only
+// the tracks the artist is performing in are kept
+func (c *Client) ArtistTracks(uri string, groups ...string) ([]*track.Track, error) {
+	albums, err := c.ArtistAlbums(uri, groups...)
+	if err != nil {
+		return nil, err
+	}
+
+	var tracks []*Track
+	for _, album := range albums {
+		albumTracks, err := c.albumTracks(album.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range albumTracks {
+			if album.AlbumGroup == "album" || album.AlbumGroup == "single" || performing(t, IDFromURI(uri)) {
+				tracks = append(tracks, t)
+			}
+		}
+	}
+
+	return c.ParseTracks(tracks), nil
+}
+
+func (c *Client) albumTracks(id ID) ([]*Track, error) {
+	var (
+		tracks     []*Track
+		iterations int
+		attempts   int
+		options    = defaultOptions()
+	)
+
+	for true {
+		*options.Offset = *options.Limit * iterations
+		chunk, err := c.GetAlbumTracksOpt(id, &options)
+		if err != nil {
+			switch kind, err := c.handleError(err, &attempts); kind {
+			case errorStrict:
+				return nil, err
+			case errorRelaxed:
+				continue
 			}
+		}
+		attempts = 0
 
-			tracks = append(tracks, track.ParseSpotifyTrack(t, tAlbum))
+		var ids []ID
+		for _, t := range chunk.Tracks {
+			ids = append(ids, t.ID)
 		}
 
+		chunkTracks, err := c.Tracks(ids)
+		if err != nil {
+			return nil, err
+		}
+		tracks = append(tracks, chunkTracks...)
+
 		if len(chunk.Tracks) < 50 {
 			break
 		}
@@ -251,6 +674,10 @@ func (c *Client) AlbumTracks(uri string) ([]*track.Track, error) {
 
 // IDFromURI returns a Spotify ID from URI string
 func IDFromURI(uri string) ID {
+	if resource, err := ParseResource(uri); err == nil {
+		return resource.ID
+	}
+
 	if strings.Count(uri, ":") == 0 {
 		return ID(uri)
 	}
@@ -259,6 +686,82 @@ func IDFromURI(uri string) ID {
 	return ID(parts[len(parts)-1])
 }
 
+// ParseTracks turns given Spotify tracks into Track objects,
+// looking up all of their albums at once
+func (c *Client) ParseTracks(spotifyTracks []*Track) []*track.Track {
+	var ids []ID
+	for _, t := range spotifyTracks {
+		ids = append(ids, t.Album.ID)
+	}
+
+	// albums which could not be fetched are left empty
+	albums, _ := c.Albums(ids)
+
+	// albums rarely have genres: fall back to the ones of their main artists
+	var artistIDs []ID
+	for _, t := range spotifyTracks {
+		if album := albums[t.Album.ID]; (album == nil || len(album.Genres) == 0) && len(t.Artists) > 0 {
+			artistIDs = append(artistIDs, t.Artists[0].ID)
+		}
+	}
+	genres, _ := c.ArtistsGenres(artistIDs)
+
+	var tracks []*track.Track
+	for _, t := range spotifyTracks {
+		tAlbum := albums[t.Album.ID]
+		if tAlbum == nil {
+			tAlbum = &Album{}
+		}
+
+		tTrack := track.ParseSpotifyTrack(t, tAlbum)
+		tTrack.Label = c.labels[t.Album.ID]
+		if len(tTrack.Genres) == 0 && len(t.Artists) > 0 {
+			tTrack.Genres = genres[t.Artists[0].ID]
+		}
+		tracks = append(tracks, tTrack)
+	}
+
+	return tracks
+}
+
+func albumTypes(groups []string) ([]spotify.AlbumType, error) {
+	var types []spotify.AlbumType
+	for _, group := range groups {
+		switch strings.ToLower(strings.TrimSpace(group)) {
+		case "album":
+			types = append(types, spotify.AlbumTypeAlbum)
+		case "single":
+			types = append(types, spotify.AlbumTypeSingle)
+		case "compilation":
+			types = append(types, spotify.AlbumTypeCompilation)
+		case "appears_on":
+			types = append(types, spotify.AlbumTypeAppearsOn)
+		default:
+			return nil, fmt.Errorf("Unknown album group: %s", group)
+		}
+	}
+	return types, nil
+}
+
+func performing(t *Track, artist ID) bool {
+	for _, a := range t.Artists {
+		if a.ID == artist {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) memoAlbum(album *Album, label string) {
+	if c.albums == nil {
+		c.albums = make(map[ID]*Album)
+		c.labels = make(map[ID]string)
+	}
+
+	c.albums[album.ID] = album
+	c.labels[album.ID] = label
+}
+
 func defaultOptions() spotify.Options {
 	var (
 		optLimit  = 50
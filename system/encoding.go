@@ -3,6 +3,8 @@ package system
 import (
 	"unicode"
 
+	"github.com/agnivade/levenshtein"
+	"github.com/gosimple/slug"
 	"golang.org/x/text/transform"
 	"golang.org/x/text/unicode/norm"
 )
@@ -15,3 +17,18 @@ func Asciify(dirty string) string {
 	clean, _, _ := transform.String(t, dirty)
 	return clean
 }
+
+// Similarity returns a score, from 0 to 100, telling how much
+// given strings are resembling each other, once slugified
+func Similarity(a, b string) int {
+	a, b = slug.Make(a), slug.Make(b)
+	if len(a) == 0 && len(b) == 0 {
+		return 100
+	}
+
+	length := len(a)
+	if len(b) > length {
+		length = len(b)
+	}
+	return 100 - levenshtein.ComputeDistance(a, b)*100/length
+}
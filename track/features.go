@@ -0,0 +1,43 @@
+package track
+
+import (
+	"strconv"
+
+	"github.com/zmb3/spotify"
+)
+
+var (
+	keyNames = []string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+	// Camelot wheel numbers, indexed by pitch class
+	keyCamelotMajor = []int{8, 3, 10, 5, 12, 7, 2, 9, 4, 11, 6, 1}
+	keyCamelotMinor = []int{5, 12, 7, 2, 9, 4, 11, 6, 1, 8, 3, 10}
+)
+
+// SetAudioFeatures sets given Spotify audio features into the track,
+// writing its key in standard notation (e.g. "F#m") or in Camelot one (e.g. "11A")
+func (track *Track) SetAudioFeatures(features *spotify.AudioFeatures, camelot bool) {
+	track.BPM = int(features.Tempo + 0.5)
+	track.Key = KeyNotation(features.Key, features.Mode, camelot)
+	track.Energy = float64(features.Energy)
+	track.Danceability = float64(features.Danceability)
+	track.Valence = float64(features.Valence)
+	track.Acousticness = float64(features.Acousticness)
+}
+
+// KeyNotation returns the notation of the key with given pitch class
+// and mode (1 for major, 0 for minor), as standard or Camelot one
+func KeyNotation(key, mode int, camelot bool) string {
+	if key < 0 || key >= len(keyNames) {
+		// off key
+		return "o"
+	}
+
+	if camelot && mode == 1 {
+		return strconv.Itoa(keyCamelotMajor[key]) + "B"
+	} else if camelot {
+		return strconv.Itoa(keyCamelotMinor[key]) + "A"
+	} else if mode == 1 {
+		return keyNames[key]
+	}
+	return keyNames[key] + "m"
+}
@@ -2,6 +2,7 @@ package track
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/gosimple/slug"
@@ -21,13 +22,18 @@ func (track *Track) Local() bool {
 
 // Basename returns track basename
 func (track Track) Basename() string {
-	basename := track.Artist + " - " + track.Title
+	return Sanitize(track.Artist + " - " + track.Title)
+}
+
+// Sanitize strips from given name all the symbols
+// which are not safe to be used in filenames
+func Sanitize(name string) string {
 	for _, symbol := range symbolsStrip {
-		basename = strings.Replace(basename, symbol, "", -1)
+		name = strings.Replace(name, symbol, "", -1)
 	}
-	basename = strings.Replace(basename, "  ", " ", -1)
-	basename = system.Asciify(basename)
-	return strings.TrimSpace(basename)
+	name = strings.Replace(name, "  ", " ", -1)
+	name = system.Asciify(name)
+	return strings.TrimSpace(name)
 }
 
 // Query returns string used to search song online
@@ -37,7 +43,18 @@ func (track Track) Query() string {
 
 // Filename returns track filename
 func (track Track) Filename() string {
-	return fmt.Sprintf("%s.%s", track.Basename(), extension)
+	return filepath.Join(track.Folder, fmt.Sprintf("%s.%s", track.Basename(), extension))
+}
+
+// FilenameLyrics returns track synced lyrics sidecar filename
+func (track Track) FilenameLyrics() string {
+	return LyricsFilename(track.Filename())
+}
+
+// LyricsFilename returns the synced lyrics sidecar filename
+// of the song at given path
+func LyricsFilename(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".lrc"
 }
 
 // FilenameTemporary returns track temporary filename
@@ -7,6 +7,37 @@ import (
 	"github.com/bogem/id3v2"
 )
 
+const (
+	userFrameSong        = "SONG"
+	userFrameFeaturings  = "FEATURINGS"
+	userFrameTrackTotals = "TRACK_TOTALS"
+	userFrameArtworkURL  = "ARTWORK_URL"
+	userFrameOrigin      = "SOURCE_URL"
+	userFrameDuration    = "DURATION"
+	userFrameSpotifyID   = "SPOTIFY_TRACK_ID"
+)
+
+var (
+	// comment frames descriptions used before user defined
+	// text frames, mapped to the ones replacing them
+	legacyFrames = map[string]string{
+		"song":        userFrameSong,
+		"featurings":  userFrameFeaturings,
+		"trackTotals": userFrameTrackTotals,
+		"artwork":     userFrameArtworkURL,
+		"origin":      userFrameOrigin,
+		"duration":    userFrameDuration,
+		"spotifyid":   userFrameSpotifyID,
+	}
+)
+
+const (
+	// ID3v2.4 multi-value text frames separate their values with null bytes,
+	// while ID3v2.3 ones, by convention, with slashes
+	valuesSeparator    = "\x00"
+	valuesSeparatorV23 = "/"
+)
+
 const (
 	// ID3FrameTitle is the ID3 title frame tag identifier
 	ID3FrameTitle = iota
@@ -38,10 +69,37 @@ const (
 	ID3FrameDuration
 	// ID3FrameSpotifyID is the ID3 Spotify ID frame tag identifier
 	ID3FrameSpotifyID
+	// ID3FrameDescription is the ID3 description frame tag identifier
+	ID3FrameDescription
+	// ID3FrameAlbumArtist is the ID3 album artist frame tag identifier
+	ID3FrameAlbumArtist
+	// ID3FrameDiscNumber is the ID3 disc number frame tag identifier
+	ID3FrameDiscNumber
+	// ID3FrameISRC is the ID3 ISRC frame tag identifier
+	ID3FrameISRC
+	// ID3FrameLabel is the ID3 label frame tag identifier
+	ID3FrameLabel
+	// ID3FrameCopyright is the ID3 copyright frame tag identifier
+	ID3FrameCopyright
+	// ID3FrameBPM is the ID3 beats per minute frame tag identifier
+	ID3FrameBPM
+	// ID3FrameKey is the ID3 initial key frame tag identifier
+	ID3FrameKey
+	// ID3FrameEnergy is the ID3 energy frame tag identifier
+	ID3FrameEnergy
+	// ID3FrameDanceability is the ID3 danceability frame tag identifier
+	ID3FrameDanceability
+	// ID3FrameValence is the ID3 valence frame tag identifier
+	ID3FrameValence
+	// ID3FrameAcousticness is the ID3 acousticness frame tag identifier
+	ID3FrameAcousticness
+	// ID3FrameSyncedLyrics is the ID3 synchronised lyrics frame tag identifier
+	ID3FrameSyncedLyrics
 )
 
-// Flush persists tracks frames into given open Tag
-func (track Track) Flush() error {
+// Flush persists tracks frames into track temporary file
+// according to given SyncOptions
+func (track Track) Flush(opts *SyncOptions) error {
 	tag, err := id3v2.Open(track.FilenameTemporary(), id3v2.Options{Parse: true})
 	if err != nil {
 		return err
@@ -50,11 +108,23 @@ func (track Track) Flush() error {
 	defer tag.Save()
 
 	// official metadata fields
-	tag.SetTitle(track.Title)
-	tag.SetArtist(track.Artist)
+	separator := tagSeparator(tag)
+	if opts.FeaturingsTitle {
+		tag.SetTitle(track.Title)
+		tag.SetArtist(track.Artist)
+	} else {
+		tag.SetTitle(track.Song)
+		tag.SetArtist(strings.Join(append([]string{track.Artist}, track.Featurings...), separator))
+	}
 	tag.SetAlbum(track.Album)
-	tag.SetGenre(track.Genre)
-	tag.SetYear(track.Year)
+	tag.SetGenre(strings.Join(track.Genres, separator))
+	// year frame is TYER on v2.3, which only holds the year,
+	// and TDRC on v2.4, which can hold the full release date
+	if tag.Version() >= 4 && len(track.ReleaseDate) > 0 {
+		tag.SetYear(track.ReleaseDate)
+	} else {
+		tag.SetYear(track.Year)
+	}
 	tag.AddFrame(
 		tag.CommonID("Track number/Position in set"),
 		id3v2.TextFrame{
@@ -62,12 +132,53 @@ func (track Track) Flush() error {
 			Text:     strconv.Itoa(track.TrackNumber),
 		},
 	)
+	for id, text := range map[string]string{
+		"Band/Orchestra/Accompaniment": track.AlbumArtist,
+		"Part of a set":                discPosition(track.DiscNumber, track.DiscTotals),
+		"ISRC":                         track.ISRC,
+		"Publisher":                    track.Label,
+		"Copyright message":            track.Copyright,
+	} {
+		if len(text) > 0 {
+			tag.AddFrame(tag.CommonID(id), id3v2.TextFrame{
+				Encoding: id3v2.EncodingUTF8,
+				Text:     text,
+			})
+		}
+	}
+	// audio features are set only if they have been fetched
+	if len(track.Key) > 0 {
+		for id, text := range map[string]string{
+			"BPM":         strconv.Itoa(track.BPM),
+			"Initial key": track.Key,
+		} {
+			tag.AddFrame(tag.CommonID(id), id3v2.TextFrame{
+				Encoding: id3v2.EncodingUTF8,
+				Text:     text,
+			})
+		}
+		for description, value := range map[string]float64{
+			"ENERGY":       track.Energy,
+			"DANCEABILITY": track.Danceability,
+			"VALENCE":      track.Valence,
+			"ACOUSTICNESS": track.Acousticness,
+		} {
+			tag.AddUserDefinedTextFrame(id3v2.UserDefinedTextFrame{
+				Encoding:    id3v2.EncodingUTF8,
+				Description: description,
+				Value:       strconv.FormatFloat(value, 'f', 3, 64),
+			})
+		}
+	}
 	tag.AddUnsynchronisedLyricsFrame(id3v2.UnsynchronisedLyricsFrame{
 		Encoding:          id3v2.EncodingUTF8,
 		Language:          "eng",
 		ContentDescriptor: track.Title,
 		Lyrics:            track.Lyrics,
 	})
+	if len(track.SyncedLyrics) > 0 {
+		tagSetSyncedLyrics(tag, track.SyncedLyrics)
+	}
 	tag.AddAttachedPicture(id3v2.PictureFrame{
 		Encoding:    id3v2.EncodingUTF8,
 		MimeType:    "image/jpeg",
@@ -75,28 +186,65 @@ func (track Track) Flush() error {
 		Description: "Front cover",
 		Picture:     *track.Artwork,
 	})
+	if len(track.Description) > 0 {
+		tag.AddCommentFrame(id3v2.CommentFrame{
+			Encoding:    id3v2.EncodingUTF8,
+			Language:    "eng",
+			Description: "",
+			Text:        track.Description,
+		})
+	}
 
 	// unofficial metadata fields
-	for key, value := range map[string]string{
-		"song":        track.Song,
-		"featurings":  strings.Join(track.Featurings, "|"),
-		"trackTotals": strconv.Itoa(track.TrackTotals),
-		"artwork":     track.ArtworkURL,
-		"origin":      track.URL,
-		"duration":    strconv.Itoa(track.Duration),
-		"spotifyid":   track.SpotifyID,
+	tagDropLegacyFrames(tag)
+	for description, value := range map[string]string{
+		userFrameSong:        track.Song,
+		userFrameFeaturings:  strings.Join(track.Featurings, "|"),
+		userFrameTrackTotals: strconv.Itoa(track.TrackTotals),
+		userFrameArtworkURL:  track.ArtworkURL,
+		userFrameOrigin:      track.URL,
+		userFrameDuration:    strconv.Itoa(track.Duration),
+		userFrameSpotifyID:   track.SpotifyID,
 	} {
-		tag.AddCommentFrame(id3v2.CommentFrame{
+		tag.AddUserDefinedTextFrame(id3v2.UserDefinedTextFrame{
 			Encoding:    id3v2.EncodingUTF8,
-			Language:    "eng",
-			Description: key,
-			Text:        value,
+			Description: description,
+			Value:       value,
 		})
 	}
 
 	return nil
 }
 
+// MigrateTags rewrites in place the legacy comment frames of the file
+// at given path as user defined text frames, returning whether any
+// frame has been migrated
+func MigrateTags(path string) (bool, error) {
+	tag, err := id3v2.Open(path, id3v2.Options{Parse: true})
+	if err != nil {
+		return false, err
+	}
+	defer tag.Close()
+
+	legacy := tagDropLegacyFrames(tag)
+	if len(legacy) == 0 {
+		return false, nil
+	}
+
+	for description, value := range legacy {
+		// frames already migrated have the precedence
+		if len(tagGetFrameUserText(tag, description)) == 0 {
+			tag.AddUserDefinedTextFrame(id3v2.UserDefinedTextFrame{
+				Encoding:    id3v2.EncodingUTF8,
+				Description: description,
+				Value:       value,
+			})
+		}
+	}
+
+	return true, tag.Save()
+}
+
 // GetTag opens, parses and returns given path's given frame tag
 func GetTag(path string, frame int) string {
 	tag, err := id3v2.Open(path, id3v2.Options{Parse: true})
@@ -114,9 +262,9 @@ func TagGetFrame(tag *id3v2.Tag, frame int) string {
 	case ID3FrameTitle:
 		return tag.Title()
 	case ID3FrameSong:
-		return tagGetFrameSong(tag)
+		return tagGetFrameCustom(tag, userFrameSong)
 	case ID3FrameArtist:
-		return tag.Artist()
+		return tagGetFrameArtist(tag)
 	case ID3FrameAlbum:
 		return tag.Album()
 	case ID3FrameGenre:
@@ -124,47 +272,49 @@ func TagGetFrame(tag *id3v2.Tag, frame int) string {
 	case ID3FrameYear:
 		return tag.Year()
 	case ID3FrameFeaturings:
-		return tagGetFrameFeaturings(tag)
+		return tagGetFrameCustom(tag, userFrameFeaturings)
 	case ID3FrameTrackNumber:
 		return tagGetFrameTrackNumber(tag)
 	case ID3FrameTrackTotals:
-		return tagGetFrameTrackTotals(tag)
+		return tagGetFrameCustom(tag, userFrameTrackTotals)
 	case ID3FrameArtwork:
 		return tagGetFrameArtwork(tag)
 	case ID3FrameArtworkURL:
-		return tagGetFrameArtworkURL(tag)
+		return tagGetFrameCustom(tag, userFrameArtworkURL)
 	case ID3FrameLyrics:
 		return tagGetFrameLyrics(tag)
 	case ID3FrameOrigin:
-		return tagGetFrameOrigin(tag)
+		return tagGetFrameCustom(tag, userFrameOrigin)
 	case ID3FrameDuration:
-		return tagGetFrameDuration(tag)
+		return tagGetFrameCustom(tag, userFrameDuration)
 	case ID3FrameSpotifyID:
-		return tagGetFrameSpotifyID(tag)
-	}
-	return ""
-}
-
-func tagGetFrameSong(tag *id3v2.Tag) string {
-	if len(tag.GetFrames(tag.CommonID("Comments"))) > 0 {
-		for _, frameComment := range tag.GetFrames(tag.CommonID("Comments")) {
-			comment, ok := frameComment.(id3v2.CommentFrame)
-			if ok && comment.Description == "song" {
-				return comment.Text
-			}
-		}
-	}
-	return ""
-}
-
-func tagGetFrameFeaturings(tag *id3v2.Tag) string {
-	if len(tag.GetFrames(tag.CommonID("Comments"))) > 0 {
-		for _, frameComment := range tag.GetFrames(tag.CommonID("Comments")) {
-			comment, ok := frameComment.(id3v2.CommentFrame)
-			if ok && comment.Description == "featurings" {
-				return comment.Text
-			}
-		}
+		return tagGetFrameCustom(tag, userFrameSpotifyID)
+	case ID3FrameDescription:
+		return tagGetFrameDescription(tag)
+	case ID3FrameAlbumArtist:
+		return tagGetFrameText(tag, "Band/Orchestra/Accompaniment")
+	case ID3FrameDiscNumber:
+		return tagGetFrameText(tag, "Part of a set")
+	case ID3FrameISRC:
+		return tagGetFrameText(tag, "ISRC")
+	case ID3FrameLabel:
+		return tagGetFrameText(tag, "Publisher")
+	case ID3FrameCopyright:
+		return tagGetFrameText(tag, "Copyright message")
+	case ID3FrameBPM:
+		return tagGetFrameText(tag, "BPM")
+	case ID3FrameKey:
+		return tagGetFrameText(tag, "Initial key")
+	case ID3FrameEnergy:
+		return tagGetFrameUserText(tag, "ENERGY")
+	case ID3FrameDanceability:
+		return tagGetFrameUserText(tag, "DANCEABILITY")
+	case ID3FrameValence:
+		return tagGetFrameUserText(tag, "VALENCE")
+	case ID3FrameAcousticness:
+		return tagGetFrameUserText(tag, "ACOUSTICNESS")
+	case ID3FrameSyncedLyrics:
+		return tagGetFrameSyncedLyrics(tag)
 	}
 	return ""
 }
@@ -181,18 +331,6 @@ func tagGetFrameTrackNumber(tag *id3v2.Tag) string {
 	return ""
 }
 
-func tagGetFrameTrackTotals(tag *id3v2.Tag) string {
-	if len(tag.GetFrames(tag.CommonID("Comments"))) > 0 {
-		for _, frameComment := range tag.GetFrames(tag.CommonID("Comments")) {
-			comment, ok := frameComment.(id3v2.CommentFrame)
-			if ok && comment.Description == "trackTotals" {
-				return comment.Text
-			}
-		}
-	}
-	return ""
-}
-
 func tagGetFrameArtwork(tag *id3v2.Tag) string {
 	if len(tag.GetFrames(tag.CommonID("Attached picture"))) > 0 {
 		for _, framePicture := range tag.GetFrames(tag.CommonID("Attached picture")) {
@@ -205,18 +343,6 @@ func tagGetFrameArtwork(tag *id3v2.Tag) string {
 	return ""
 }
 
-func tagGetFrameArtworkURL(tag *id3v2.Tag) string {
-	if len(tag.GetFrames(tag.CommonID("Comments"))) > 0 {
-		for _, frameComment := range tag.GetFrames(tag.CommonID("Comments")) {
-			comment, ok := frameComment.(id3v2.CommentFrame)
-			if ok && comment.Description == "artwork" {
-				return comment.Text
-			}
-		}
-	}
-	return ""
-}
-
 func tagGetFrameLyrics(tag *id3v2.Tag) string {
 	if len(tag.GetFrames(tag.CommonID("Unsynchronised lyrics/text transcription"))) > 0 {
 		for _, frameLyrics := range tag.GetFrames(tag.CommonID("Unsynchronised lyrics/text transcription")) {
@@ -229,11 +355,11 @@ func tagGetFrameLyrics(tag *id3v2.Tag) string {
 	return ""
 }
 
-func tagGetFrameOrigin(tag *id3v2.Tag) string {
+func tagGetFrameDescription(tag *id3v2.Tag) string {
 	if len(tag.GetFrames(tag.CommonID("Comments"))) > 0 {
 		for _, frameComment := range tag.GetFrames(tag.CommonID("Comments")) {
 			comment, ok := frameComment.(id3v2.CommentFrame)
-			if ok && comment.Description == "origin" {
+			if ok && comment.Description == "" {
 				return comment.Text
 			}
 		}
@@ -241,11 +367,59 @@ func tagGetFrameOrigin(tag *id3v2.Tag) string {
 	return ""
 }
 
-func tagGetFrameDuration(tag *id3v2.Tag) string {
-	if len(tag.GetFrames(tag.CommonID("Comments"))) > 0 {
+// tagSeparator returns the separator multiple values
+// of a text frame are joined with, on given tag version
+func tagSeparator(tag *id3v2.Tag) string {
+	if tag.Version() < 4 {
+		return valuesSeparatorV23
+	}
+	return valuesSeparator
+}
+
+// tagGetFrameArtist returns the main artist out of the artist frame, which
+// can be holding the featurings too: on v2.3, these are stripped as written,
+// as the separator could be part of the artist name itself
+func tagGetFrameArtist(tag *id3v2.Tag) string {
+	var (
+		artist     = tag.Artist()
+		separator  = tagSeparator(tag)
+		featurings = splitValues(tagGetFrameCustom(tag, userFrameFeaturings), "|")
+	)
+	if len(featurings) > 0 {
+		artist = strings.TrimSuffix(artist, separator+strings.Join(featurings, separator))
+	}
+	return strings.Split(artist, valuesSeparator)[0]
+}
+
+func splitValues(text, separator string) []string {
+	var values []string
+	for _, value := range strings.Split(text, separator) {
+		if value = strings.TrimSpace(value); len(value) > 0 {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func tagGetFrameText(tag *id3v2.Tag, description string) string {
+	return tag.GetTextFrame(tag.CommonID(description)).Text
+}
+
+// tagGetFrameCustom returns the value of the user defined text frame with
+// given description, falling back to the legacy comment frame it replaced
+func tagGetFrameCustom(tag *id3v2.Tag, description string) string {
+	if value := tagGetFrameUserText(tag, description); len(value) > 0 {
+		return value
+	}
+
+	for legacy, userFrame := range legacyFrames {
+		if userFrame != description {
+			continue
+		}
+
 		for _, frameComment := range tag.GetFrames(tag.CommonID("Comments")) {
 			comment, ok := frameComment.(id3v2.CommentFrame)
-			if ok && comment.Description == "duration" {
+			if ok && comment.Description == legacy {
 				return comment.Text
 			}
 		}
@@ -253,18 +427,55 @@ func tagGetFrameDuration(tag *id3v2.Tag) string {
 	return ""
 }
 
-func tagGetFrameSpotifyID(tag *id3v2.Tag) string {
-	if len(tag.GetFrames(tag.CommonID("Comments"))) > 0 {
-		for _, frameComment := range tag.GetFrames(tag.CommonID("Comments")) {
-			comment, ok := frameComment.(id3v2.CommentFrame)
-			if ok && comment.Description == "spotifyid" {
-				return comment.Text
-			}
+// tagDropLegacyFrames deletes the legacy comment frames from given tag,
+// returning their values mapped to the user defined text frames replacing them
+func tagDropLegacyFrames(tag *id3v2.Tag) map[string]string {
+	var (
+		legacy = make(map[string]string)
+		keep   []id3v2.CommentFrame
+	)
+	for _, frameComment := range tag.GetFrames(tag.CommonID("Comments")) {
+		comment, ok := frameComment.(id3v2.CommentFrame)
+		if !ok {
+			continue
+		}
+
+		if userFrame, isLegacy := legacyFrames[comment.Description]; isLegacy {
+			legacy[userFrame] = comment.Text
+		} else {
+			keep = append(keep, comment)
+		}
+	}
+
+	if len(legacy) > 0 {
+		tag.DeleteFrames(tag.CommonID("Comments"))
+		for _, comment := range keep {
+			tag.AddCommentFrame(comment)
+		}
+	}
+
+	return legacy
+}
+
+func tagGetFrameUserText(tag *id3v2.Tag, description string) string {
+	for _, frameText := range tag.GetFrames(tag.CommonID("User defined text information frame")) {
+		text, ok := frameText.(id3v2.UserDefinedTextFrame)
+		if ok && text.Description == description {
+			return text.Value
 		}
 	}
 	return ""
 }
 
+func discPosition(number, totals int) string {
+	if number == 0 {
+		return ""
+	} else if totals == 0 {
+		return strconv.Itoa(number)
+	}
+	return strconv.Itoa(number) + "/" + strconv.Itoa(totals)
+}
+
 func (track Track) getID3Frame(frame int) string {
 	tag, err := id3v2.Open(track.Filename(), id3v2.Options{Parse: true})
 	if tag == nil || err != nil {
@@ -72,3 +72,8 @@ func (index *TracksIndex) Rename(id string, filename string) {
 		index.Tracks[id] = path
 	}
 }
+
+// Remove drops input id element from the index
+func (index *TracksIndex) Remove(id string) {
+	delete(index.Tracks, id)
+}
@@ -3,7 +3,10 @@ package track
 import (
 	"fmt"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/streambinder/spotitube/system"
 )
@@ -13,14 +16,160 @@ type Playlist struct {
 	Tracks []*Track
 	Name   string
 	Owner  string
+	Folder string
+}
+
+var (
+	regPLSEntry = regexp.MustCompile(`^File(\d+)=(.+)$`)
+	regPLSField = regexp.MustCompile(`^(File|Title|Length)(\d+)=(.*)$`)
+	regPLSName  = regexp.MustCompile(`^\[(.+)\]$`)
+)
+
+// ReadPlaylist parses the M3U or PLS playlist file at given path,
+// returning its name and the paths of its entries, in order
+func ReadPlaylist(path string) (string, []string, error) {
+	if !system.FileExists(path) {
+		return "", nil, fmt.Errorf(fmt.Sprintf("%s does not exist", path))
+	}
+
+	var (
+		name    = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		isPLS   = strings.EqualFold(filepath.Ext(path), ".pls")
+		entries []string
+		pls     = make(map[int]string)
+	)
+	for _, line := range system.FileReadLines(path) {
+		line = strings.TrimSpace(line)
+		switch {
+		case len(line) == 0:
+			continue
+		case !isPLS && strings.HasPrefix(line, "#PLAYLIST:"):
+			name = strings.TrimSpace(strings.TrimPrefix(line, "#PLAYLIST:"))
+		case !isPLS && strings.HasPrefix(line, "#"):
+			continue
+		case !isPLS:
+			entries = append(entries, line)
+		case regPLSName.MatchString(line):
+			if header := regPLSName.FindStringSubmatch(line)[1]; !strings.EqualFold(header, "playlist") {
+				name = header
+			}
+		case regPLSEntry.MatchString(line):
+			match := regPLSEntry.FindStringSubmatch(line)
+			if index, err := strconv.Atoi(match[1]); err == nil {
+				pls[index] = match[2]
+			}
+		}
+	}
+
+	// PLS entries are numbered, not necessarily in order
+	var indexes []int
+	for index := range pls {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		entries = append(entries, pls[index])
+	}
+
+	// entries are relative to the playlist file
+	for i, entry := range entries {
+		if !filepath.IsAbs(entry) {
+			entries[i] = filepath.Join(filepath.Dir(path), entry)
+		}
+	}
+
+	return name, entries, nil
+}
+
+// StripPlaylist drops from the M3U or PLS playlist file at given path
+// the entries pointing to any of the given absolute paths, returning
+// how many entries have been dropped
+func StripPlaylist(path string, paths map[string]bool) (int, error) {
+	var (
+		isPLS    = strings.EqualFold(filepath.Ext(path), ".pls")
+		lines    = system.FileReadLines(path)
+		kept     []string
+		stripped = make(map[string]bool)
+	)
+	strip := func(entry string) bool {
+		if !filepath.IsAbs(entry) {
+			entry = filepath.Join(filepath.Dir(path), entry)
+		}
+		entryAbs, err := filepath.Abs(entry)
+		return err == nil && paths[entryAbs]
+	}
+
+	if !isPLS {
+		var count int
+		for _, line := range lines {
+			trimmed := strings.TrimSpace(line)
+			if len(trimmed) == 0 || strings.HasPrefix(trimmed, "#") || !strip(trimmed) {
+				kept = append(kept, line)
+				continue
+			}
+
+			count++
+			// entry information line goes along with the entry
+			if len(kept) > 0 && strings.HasPrefix(kept[len(kept)-1], "#EXTINF:") {
+				kept = kept[:len(kept)-1]
+			}
+		}
+		if count == 0 {
+			return 0, nil
+		}
+		return count, system.FileWriteLines(path, kept)
+	}
+
+	// PLS fields are numbered after their entry, hence
+	// the entries left have to be numbered again
+	var indexes []int
+	for _, line := range lines {
+		if match := regPLSEntry.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			if strip(match[2]) {
+				stripped[match[1]] = true
+			} else if index, err := strconv.Atoi(match[1]); err == nil {
+				indexes = append(indexes, index)
+			}
+		}
+	}
+	if len(stripped) == 0 {
+		return 0, nil
+	}
+
+	sort.Ints(indexes)
+	renumber := make(map[string]string)
+	for i, index := range indexes {
+		renumber[strconv.Itoa(index)] = strconv.Itoa(i + 1)
+	}
+
+	var skipBlank bool
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch match := regPLSField.FindStringSubmatch(trimmed); {
+		case match != nil && stripped[match[2]]:
+			skipBlank = true
+			continue
+		case match != nil:
+			line = fmt.Sprintf("%s%s=%s", match[1], renumber[match[2]], match[3])
+		case strings.HasPrefix(trimmed, "NumberOfEntries="):
+			line = fmt.Sprintf("NumberOfEntries=%d", len(indexes))
+		case len(trimmed) == 0 && skipBlank:
+			skipBlank = false
+			continue
+		}
+		skipBlank = false
+		kept = append(kept, line)
+	}
+
+	return len(stripped), system.FileWriteLines(path, kept)
 }
 
 // M3U returns the M3U-compliant representation of the playlist
 func (p *Playlist) M3U(prefix string) string {
 	content := "#EXTM3U\n"
 	for _, t := range p.Tracks {
-		fname := prefix + string(filepath.Separator) + t.Filename()
-		if system.FileExists(fname) {
+		fname := prefix + string(filepath.Separator) + p.relative(t)
+		if system.FileExists(t.Filename()) {
 			content += fmt.Sprintf("#EXTINF:%s,%s\n%s\n",
 				strconv.Itoa(t.Duration),
 				t.Basename(),
@@ -35,8 +184,8 @@ func (p *Playlist) M3U(prefix string) string {
 func (p *Playlist) PLS(prefix string) string {
 	content := fmt.Sprintf("[%s]\n", p.Name)
 	for i, t := range p.Tracks {
-		fname := prefix + string(filepath.Separator) + t.Filename()
-		if system.FileExists(fname) {
+		fname := prefix + string(filepath.Separator) + p.relative(t)
+		if system.FileExists(t.Filename()) {
 			content += fmt.Sprintf("File%s=%s\nTitle%s=%s\nLength%s=%s\n\n",
 				strconv.Itoa(i+1),
 				fname,
@@ -50,3 +199,12 @@ func (p *Playlist) PLS(prefix string) string {
 
 	return content
 }
+
+// relative returns the given track filename, relative to the playlist folder
+func (p *Playlist) relative(t *Track) string {
+	if fname, err := filepath.Rel(filepath.Clean(p.Folder), t.Filename()); err == nil {
+		return fname
+	}
+
+	return t.Filename()
+}
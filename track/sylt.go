@@ -0,0 +1,120 @@
+package track
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"time"
+
+	"github.com/bogem/id3v2"
+	"github.com/streambinder/spotitube/lyrics"
+	"github.com/streambinder/spotitube/system"
+)
+
+const (
+	syltFrameID = "SYLT"
+	// encodings as defined by ID3 specifications
+	syltEncodingISO  = 0
+	syltEncodingUTF8 = 3
+	// absolute time, using milliseconds as unit
+	syltTimestampFormat = 2
+	syltContentLyrics   = 1
+)
+
+// syncedLyricsFrame is the SYLT frame implementation, which
+// the id3v2 library does not provide
+type syncedLyricsFrame struct {
+	encoding byte
+	language string
+	lines    lyrics.Synced
+}
+
+func (f syncedLyricsFrame) Size() int {
+	return len(f.body())
+}
+
+func (f syncedLyricsFrame) UniqueIdentifier() string {
+	return f.language
+}
+
+func (f syncedLyricsFrame) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(f.body())
+	return int64(n), err
+}
+
+func (f syncedLyricsFrame) body() []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(f.encoding)
+	buf.WriteString(f.language)
+	buf.WriteByte(syltTimestampFormat)
+	buf.WriteByte(syltContentLyrics)
+	// empty content descriptor
+	buf.WriteByte(0)
+	for _, line := range f.lines {
+		text := line.Text
+		if f.encoding == syltEncodingISO {
+			text = system.Asciify(text)
+		}
+		buf.WriteString(text)
+		buf.WriteByte(0)
+		binary.Write(&buf, binary.BigEndian, uint32(line.Time/time.Millisecond))
+	}
+	return buf.Bytes()
+}
+
+// tagSetSyncedLyrics sets given time-stamped lyrics
+// into given tag, as a SYLT frame
+func tagSetSyncedLyrics(tag *id3v2.Tag, synced lyrics.Synced) {
+	frame := syncedLyricsFrame{encoding: syltEncodingUTF8, language: "eng", lines: synced}
+	// UTF-8 encoding is only supported since ID3v2.4
+	if tag.Version() < 4 {
+		frame.encoding = syltEncodingISO
+	}
+	tag.AddFrame(syltFrameID, frame)
+}
+
+// tagGetFrameSyncedLyrics returns the LRC-formatted representation
+// of the SYLT frame of given tag, if any
+func tagGetFrameSyncedLyrics(tag *id3v2.Tag) string {
+	for _, frame := range tag.GetFrames(syltFrameID) {
+		var body []byte
+		switch f := frame.(type) {
+		case id3v2.UnknownFrame:
+			body = f.Body
+		case syncedLyricsFrame:
+			body = f.body()
+		default:
+			continue
+		}
+
+		// only single byte encodings and milliseconds timestamps are supported
+		if len(body) < 6 || (body[0] != syltEncodingISO && body[0] != syltEncodingUTF8) || body[4] != syltTimestampFormat {
+			continue
+		}
+
+		var (
+			synced lyrics.Synced
+			rest   = body[6:]
+		)
+		// skip content descriptor
+		if i := bytes.IndexByte(rest, 0); i >= 0 {
+			rest = rest[i+1:]
+		}
+		for {
+			i := bytes.IndexByte(rest, 0)
+			if i < 0 || len(rest) < i+5 {
+				break
+			}
+			synced = append(synced, lyrics.Line{
+				Text: string(rest[:i]),
+				Time: time.Duration(binary.BigEndian.Uint32(rest[i+1:i+5])) * time.Millisecond,
+			})
+			rest = rest[i+5:]
+		}
+
+		if len(synced) > 0 {
+			return synced.LRC()
+		}
+	}
+	return ""
+}
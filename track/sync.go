@@ -2,9 +2,10 @@ package track
 
 // SyncOptions wraps settings for a single track synchronization
 type SyncOptions struct {
-	Source        bool
-	Metadata      bool
-	Normalization bool
+	Source          bool
+	Metadata        bool
+	Normalization   bool
+	FeaturingsTitle bool
 }
 
 // SyncOptionsFlush returns a SyncOptions pointer for flushing tracks
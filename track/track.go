@@ -2,38 +2,60 @@ package track
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/bogem/id3v2"
+	"github.com/streambinder/spotitube/lyrics"
 	"github.com/streambinder/spotitube/system"
 	"github.com/zmb3/spotify"
 )
 
 // Track represents a track
 type Track struct {
-	Album       string
-	Artist      string
-	Artwork     *[]byte
-	ArtworkURL  string
-	Duration    int
-	Featurings  []string
-	Genre       string
-	Lyrics      string
-	Song        string
-	SpotifyID   string
-	Title       string
-	TrackNumber int
-	TrackTotals int
-	URL         string
-	Year        string
+	Acousticness         float64
+	Album                string
+	AlbumArtist          string
+	Artist               string
+	Artwork              *[]byte
+	ArtworkURL           string
+	BPM                  int
+	Copyright            string
+	Danceability         float64
+	Description          string
+	DiscNumber           int
+	DiscTotals           int
+	Duration             int
+	Energy               float64
+	Featurings           []string
+	Folder               string
+	Genres               []string
+	ISRC                 string
+	Key                  string
+	Label                string
+	Lyrics               string
+	ReleaseDate          string
+	ReleaseDatePrecision string
+	Song                 string
+	SpotifyID            string
+	SyncedLyrics         lyrics.Synced
+	Title                string
+	TrackNumber          int
+	TrackTotals          int
+	URL                  string
+	Valence              float64
+	Year                 string
 }
 
 // TracksDump represents dumpable tracks
+// along with the snapshot of the source they have been read from
 type TracksDump struct {
-	Tracks []*Track
-	Time   time.Time
+	Tracks   []*Track
+	Time     time.Time
+	Snapshot string
 }
 
 // CountOffline returns the number of offline (local) songs
@@ -71,8 +93,8 @@ func OpenLocalTrack(path string) (*Track, error) {
 		Artist:      TagGetFrame(trackMp3, ID3FrameArtist),
 		Album:       TagGetFrame(trackMp3, ID3FrameAlbum),
 		Year:        TagGetFrame(trackMp3, ID3FrameYear),
-		Featurings:  strings.Split(TagGetFrame(trackMp3, ID3FrameFeaturings), "|"),
-		Genre:       TagGetFrame(trackMp3, ID3FrameGenre),
+		Featurings:  splitValues(TagGetFrame(trackMp3, ID3FrameFeaturings), "|"),
+		Genres:      splitValues(TagGetFrame(trackMp3, ID3FrameGenre), tagSeparator(trackMp3)),
 		TrackNumber: 0,
 		TrackTotals: 0,
 		Duration:    0,
@@ -80,6 +102,46 @@ func OpenLocalTrack(path string) (*Track, error) {
 		URL:         TagGetFrame(trackMp3, ID3FrameOrigin),
 		SpotifyID:   TagGetFrame(trackMp3, ID3FrameSpotifyID),
 		Lyrics:      TagGetFrame(trackMp3, ID3FrameLyrics),
+		Description: TagGetFrame(trackMp3, ID3FrameDescription),
+		AlbumArtist: TagGetFrame(trackMp3, ID3FrameAlbumArtist),
+		ISRC:        TagGetFrame(trackMp3, ID3FrameISRC),
+		Label:       TagGetFrame(trackMp3, ID3FrameLabel),
+		Copyright:   TagGetFrame(trackMp3, ID3FrameCopyright),
+		Key:         TagGetFrame(trackMp3, ID3FrameKey),
+	}
+
+	if synced, syncedErr := lyrics.ParseLRC(TagGetFrame(trackMp3, ID3FrameSyncedLyrics)); syncedErr == nil {
+		track.SyncedLyrics = synced
+	}
+
+	// title frame could be holding the song only, featurings being into the artist one
+	if len(track.Featurings) > 0 && !strings.Contains(track.Title, "(ft. ") {
+		track.Title, _ = parseTitle(track.Title, track.Featurings)
+	}
+
+	// recording time frame carries the full release date
+	track.ReleaseDate = track.Year
+	track.ReleaseDatePrecision = datePrecision(track.ReleaseDate)
+	if strings.Contains(track.Year, "-") {
+		track.Year = strings.Split(track.Year, "-")[0]
+	}
+
+	discParts := strings.Split(TagGetFrame(trackMp3, ID3FrameDiscNumber), "/")
+	if discNumber, discNumberErr := strconv.Atoi(discParts[0]); discNumberErr == nil {
+		track.DiscNumber = discNumber
+	}
+	if len(discParts) > 1 {
+		if discTotals, discTotalsErr := strconv.Atoi(discParts[1]); discTotalsErr == nil {
+			track.DiscTotals = discTotals
+		}
+	}
+
+	// keep tracks synced into subfolders, as show episodes, where they are
+	if wd, err := os.Getwd(); err == nil {
+		if folder, err := filepath.Rel(wd, filepath.Dir(path)); err == nil &&
+			folder != "." && !strings.HasPrefix(folder, "..") {
+			track.Folder = folder
+		}
 	}
 
 	if trackNumber, trackNumberErr := strconv.Atoi(TagGetFrame(trackMp3, ID3FrameTrackNumber)); trackNumberErr == nil {
@@ -91,6 +153,19 @@ func OpenLocalTrack(path string) (*Track, error) {
 	if duration, durationErr := strconv.Atoi(TagGetFrame(trackMp3, ID3FrameDuration)); durationErr == nil {
 		track.Duration = duration
 	}
+	if bpm, bpmErr := strconv.Atoi(TagGetFrame(trackMp3, ID3FrameBPM)); bpmErr == nil {
+		track.BPM = bpm
+	}
+	for frame, value := range map[int]*float64{
+		ID3FrameEnergy:       &track.Energy,
+		ID3FrameDanceability: &track.Danceability,
+		ID3FrameValence:      &track.Valence,
+		ID3FrameAcousticness: &track.Acousticness,
+	} {
+		if feature, featureErr := strconv.ParseFloat(TagGetFrame(trackMp3, frame), 64); featureErr == nil {
+			*value = feature
+		}
+	}
 
 	trackMp3.Close()
 	return &track, nil
@@ -119,12 +194,7 @@ func ParseSpotifyTrack(spotifyTrack *spotify.FullTrack, spotifyAlbum *spotify.Fu
 			}
 			return featurings
 		}(),
-		Genre: func() string {
-			if len(spotifyAlbum.Genres) > 0 {
-				return spotifyAlbum.Genres[0]
-			}
-			return ""
-		}(),
+		Genres:      spotifyAlbum.Genres,
 		TrackNumber: spotifyTrack.SimpleTrack.TrackNumber,
 		TrackTotals: len(spotifyAlbum.Tracks.Tracks),
 		Duration:    spotifyTrack.SimpleTrack.Duration / 1000,
@@ -137,6 +207,40 @@ func ParseSpotifyTrack(spotifyTrack *spotify.FullTrack, spotifyAlbum *spotify.Fu
 		URL:       "",
 		SpotifyID: spotifyTrack.SimpleTrack.ID.String(),
 		Lyrics:    "",
+		AlbumArtist: func() string {
+			if len(spotifyTrack.Album.Artists) > 0 {
+				return spotifyTrack.Album.Artists[0].Name
+			}
+			return ""
+		}(),
+		DiscNumber: spotifyTrack.SimpleTrack.DiscNumber,
+		DiscTotals: func() int {
+			// disc totals can only be told if all the album tracks are known
+			if len(spotifyAlbum.Tracks.Tracks) < spotifyAlbum.Tracks.Total {
+				return 0
+			}
+			var discTotals int
+			for _, albumTrack := range spotifyAlbum.Tracks.Tracks {
+				if albumTrack.DiscNumber > discTotals {
+					discTotals = albumTrack.DiscNumber
+				}
+			}
+			return discTotals
+		}(),
+		ISRC: spotifyTrack.ExternalIDs["isrc"],
+		Copyright: func() string {
+			for _, copyright := range spotifyAlbum.Copyrights {
+				if copyright.Type == "C" {
+					return copyright.Text
+				}
+			}
+			if len(spotifyAlbum.Copyrights) > 0 {
+				return spotifyAlbum.Copyrights[0].Text
+			}
+			return ""
+		}(),
+		ReleaseDate:          spotifyAlbum.ReleaseDate,
+		ReleaseDatePrecision: spotifyAlbum.ReleaseDatePrecision,
 	}
 
 	track.Title, track.Song = parseTitle(track.Title, track.Featurings)
@@ -146,12 +250,32 @@ func ParseSpotifyTrack(spotifyTrack *spotify.FullTrack, spotifyAlbum *spotify.Fu
 	track.Album = strings.Replace(track.Album, "{", "(", -1)
 	track.Album = strings.Replace(track.Album, "}", ")", -1)
 
+	track.ReadLocal()
+
+	return &track
+}
+
+// ReadLocal refreshes the fields which are only known from the tags
+// of the local song, clearing them if the song is not synchronized yet
+func (track *Track) ReadLocal() {
+	track.URL, track.Lyrics, track.SyncedLyrics = "", "", nil
 	if track.Local() {
 		track.URL = track.getID3Frame(ID3FrameOrigin)
 		track.Lyrics = track.getID3Frame(ID3FrameLyrics)
+		track.SyncedLyrics, _ = lyrics.ParseLRC(track.getID3Frame(ID3FrameSyncedLyrics))
 	}
+}
 
-	return &track
+func datePrecision(date string) string {
+	switch len(date) {
+	case len("2006"):
+		return "year"
+	case len("2006-01"):
+		return "month"
+	case len("2006-01-02"):
+		return "day"
+	}
+	return ""
 }
 
 func parseTitle(trackTitle string, trackFeaturings []string) (string, string) {